@@ -0,0 +1,27 @@
+package bot
+
+import (
+	"discordBot/bot/config"
+	"discordBot/bot/router"
+)
+
+// guildCheck applies the server's configuration before a command runs:
+// allowed channels and disabled modules.
+func guildCheck(guilds *config.Guilds) router.Check {
+	return func(req *router.Request) error {
+		if req.GuildID == "" {
+			if req.Command.Module == config.Module {
+				return router.Deny("This command can only be used in a server.")
+			}
+			return nil
+		}
+		cfg := guilds.Get(req.GuildID)
+		if !cfg.ChannelAllowed(req.ChannelID) {
+			return router.ErrIgnore
+		}
+		if req.Command.Module != config.Module && !cfg.ModuleEnabled(req.Command.Module) {
+			return router.Deny("The `" + req.Command.Module + "` commands are disabled in this server.")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,275 @@
+package bot
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"discordBot/bot/router"
+	"discordBot/bot/router/routertest"
+	betting "discordBot/functions/betting"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	usageReport  = "Usage: /report <uid> <amount>"
+	usagePredict = "Usage: /predict \"<match>\" <outcome> [stake] [sport_key], e.g. /predict \"arsenal spurs\" draw 50"
+	usageServers = "Usage: /servers add \"<name>\" <host> <port> [minecraft|a2s|tcp] [query_port] [group...]"
+	usageAlerts  = "Usage: /odds alerts on|off [#channel] [sport_key] [threshold]"
+
+	usagePrice        = "Usage: /price <item_name...> [game] [currency], e.g. /price ak redline ft cs2 EUR"
+	usageHistory      = "Usage: /pricehistory <item_name...> [days], e.g. /pricehistory ak redline ft 90"
+	usageWatchAdd     = "Usage: /watch add below|above <price> <item_name...>, e.g. /watch add below 12.50 ak redline ft"
+	usagePortfolioAdd = "Usage: /portfolio add <quantity> <buy_price> <item_name...>, e.g. /portfolio add 3 12.50 ak redline ft"
+	// otherChannel is a channel in some other server.
+	otherChannel = "901"
+	needsServer  = "This command needs a permission that can only be used in a server, or by a bot owner."
+	serverOnly   = "This command can only be used in a server."
+	denied       = "You don't have permission to use this command."
+	modules      = "betting, general, generators, mail, market, servers, steam, utility"
+	currencies   = "AUD|BRL|CAD|CHF|CNY|EUR|GBP|HKD|INR|JPY|KRW|MXN|NOK|NZD|PLN|RUB|SGD|TRY|UAH|USD|ZAR"
+	permissions  = "manage_config|clear_messages|steam_accounts"
+	noOddsKey    = "Failed to retrieve upcoming matches: THE_ODDS is not set"
+	leagueGuild  = "The prediction league is per server, so /predict only works in one."
+)
+
+// newTestServices opens every store in a temporary data directory with the
+// default configuration and no Odds API key, so no command reaches the network
+// by accident.
+func newTestServices(t *testing.T) *services {
+	t.Helper()
+	t.Setenv("DATA_DIR", t.TempDir())
+	t.Setenv("BOT_CONFIG", t.TempDir()+"/config.json")
+	t.Setenv("THE_ODDS", "")
+	s, err := openServices()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// replies renders what the bot sent: the text, or "embed: <title>" for an
+// embed, prefixed with "[channel] " when it went somewhere other than where
+// the command came from.
+func replies(from string, sent []routertest.Message) []string {
+	var out []string
+	for _, m := range sent {
+		text := m.Content
+		if text == "" && len(m.Embeds) > 0 {
+			text = "embed: " + m.Embeds[0].Title
+		}
+		if m.ChannelID != from {
+			text = "[" + m.ChannelID + "] " + text
+		}
+		out = append(out, text)
+	}
+	return out
+}
+
+func TestCommands(t *testing.T) {
+	season := betting.Season(time.Now())
+	tests := []struct {
+		name    string
+		content string
+		// dm sends the command in a DM instead of the test server.
+		dm bool
+		// member runs the command without server admin rights.
+		member bool
+		// setup is run by an admin in the test server first.
+		setup []string
+		want  []string
+	}{
+		// general
+		{name: "help", content: "/help", want: []string{"embed: Bot Help"}},
+		{name: "help topic", content: "/help steam", want: []string{"embed: Steam Bot Commands:"}},
+		{name: "help alias", content: "/commands", want: []string{"embed: Bot Help"}},
+		{name: "help unknown topic", content: "/help nope", want: []string{"embed: Bot Help"}},
+		{name: "dm", content: "/dm", want: []string{
+			"[dm:100] Hello! This is your DM with the bot. You can interact with me here. \n Run /help to see available commands.",
+			"I've sent you a DM!",
+		}},
+		{name: "unknown command", content: "/nope", want: nil},
+		{name: "other prefix", content: "!help", want: nil},
+
+		// utility
+		{name: "proxy bad type", content: "/proxy ftp", want: []string{"Invalid proxy type. Use: http, https, or socks5"}},
+		{name: "proxy too many args", content: "/proxy http https", want: []string{"Usage: /proxy [http|https|socks5]"}},
+		{name: "clear", content: "/clear", want: []string{"failed to clear messages!"}},
+		{name: "clear needs permission", content: "/clear", member: true, want: []string{denied}},
+		{name: "clear in dm", content: "/clear", dm: true, want: []string{needsServer}},
+
+		// steam
+		{name: "report no args", content: "/report", want: []string{usageReport}},
+		{name: "report too many args", content: "/report 1 2 3", want: []string{usageReport}},
+		{name: "report needs permission", content: "/report 1", member: true, want: []string{denied}},
+		{name: "report with granted permission", content: "/report", member: true,
+			setup: []string{"/perm grant user 100 steam_accounts"}, want: []string{usageReport}},
+		{name: "bot-add missing password", content: "/bot-add a", want: []string{"Usage: /bot-add <username> <password>"}},
+		{name: "bot-remove", content: "/bot-remove", want: []string{"Usage: /bot-remove <username>"}},
+		{name: "bot-del alias", content: "/bot-del", want: []string{"Usage: /bot-remove <username>"}},
+		{name: "bot-list without reporter", content: "/bot-list", want: []string{"Failed to list bot accounts!"}},
+		{name: "bot-list in dm", content: "/bot-list", dm: true, want: []string{needsServer}},
+
+		// generators
+		{name: "number no length", content: "/number", want: []string{"Please provide a valid length for the random number. Example: /number 5"}},
+		{name: "number zero", content: "/number 0", want: []string{"Please provide a valid positive integer for the length."}},
+		{name: "number too long", content: "/number 19", want: []string{"Please provide a number length between 1 and 18."}},
+		{name: "number not a number", content: "/number abc", want: []string{"Please provide a valid positive integer for the length."}},
+		{name: "username", content: "/username", want: []string{"Please provide a valid input for the username. Example: /username JohnDoe"}},
+		{name: "string", content: "/string", want: []string{"Please provide a valid length for the random string. Example: /string 10"}},
+		{name: "string negative", content: "/string -1", want: []string{"Please provide a valid positive integer for the length."}},
+		{name: "disabled module", content: "/number 5",
+			setup: []string{"/config module disable generators"}, want: []string{"The `generators` commands are disabled in this server."}},
+		{name: "disabled module in dm", content: "/number 0", dm: true,
+			setup: []string{"/config module disable generators"}, want: []string{"Please provide a valid positive integer for the length."}},
+
+		// mail
+		{name: "yopmail takes no args", content: "/yopmail x", want: []string{"Usage: /yopmail"}},
+		{name: "mail takes no args", content: "/mail x", want: []string{"Usage: /mail"}},
+		{name: "inbox", content: "/inbox", want: []string{"Usage: /inbox <sid_token>"}},
+		{name: "view missing token", content: "/view 1", want: []string{"Usage: /view <mail_id> <sid_token>"}},
+		{name: "del", content: "/del", want: []string{"Usage: /del <mail_id> <sid_token>"}},
+		{name: "address", content: "/address", want: []string{"Usage: /address <sid_token>"}},
+
+		// betting
+		{name: "odds without key", content: "/odds", want: []string{noOddsKey}},
+		{name: "odds bad region", content: "/odds show soccer_epl xx", want: []string{"unknown region \"xx\", use one of uk, eu, us, us2, au"}},
+		{name: "odds quota", content: "/odds quota", want: []string{
+			"No Odds API requests have been made since the bot started, so the quota isn't known yet. Odds are reused for 5m per sport, region and market.",
+		}},
+		{name: "odds arb without key", content: "/odds arb", want: []string{noOddsKey}},
+		{name: "odds value bad threshold", content: "/odds value soccer_epl uk h2h abc", want: []string{"`abc` isn't a percentage. Use a number like 5."}},
+		{name: "odds movement", content: "/odds movement", want: []string{"Usage: /odds movement <match...>"}},
+		{name: "odds movement unknown match", content: "/odds movement arsenal spurs", want: []string{
+			"No odds recorded for a match matching `arsenal spurs` yet. They're recorded whenever /odds runs and on a schedule for tracked sports.",
+		}},
+		{name: "odds alerts", content: "/odds alerts", want: []string{usageAlerts}},
+		{name: "odds alerts bad action", content: "/odds alerts maybe", want: []string{usageAlerts}},
+		{name: "odds alerts off when off", content: "/odds alerts off", want: []string{"Odds alerts weren't on in this server."}},
+		{name: "odds alerts on", content: "/odds alerts on <#201>", want: []string{"I'll post in <#201> when a best price on soccer_epl moves 10% or more within 3h. Odds are checked every 30m."}},
+		{name: "odds alerts in another server", content: "/odds alerts on <#" + otherChannel + ">", want: []string{"`" + otherChannel + "` isn't a channel in this server."}},
+		{name: "odds alerts needs permission", content: "/odds alerts off", member: true, want: []string{denied}},
+		{name: "football without key", content: "/football", want: []string{noOddsKey}},
+		{name: "predict", content: "/predict", want: []string{usagePredict}},
+		{name: "predict missing outcome", content: "/predict x", want: []string{usagePredict}},
+		{name: "predict bad stake", content: "/predict x y abc", want: []string{"`abc` isn't a stake. Use a whole number of points, e.g. 100."}},
+		{name: "predict in dm", content: "/predict x y", dm: true, want: []string{leagueGuild}},
+		{name: "predict list", content: "/predict list", want: []string{"You have no open predictions and 1000 points this season."}},
+		{name: "leaderboard empty", content: "/leaderboard", want: []string{"Nobody has made a prediction in " + season + " yet. Start with /predict."}},
+		{name: "leaderboard bad season", content: "/leaderboard 2026-13", want: []string{"`2026-13` isn't a season. Seasons are months, e.g. " + season + "."}},
+		{name: "leaderboard in dm", content: "/leaderboard", dm: true, want: []string{"The prediction league is per server, so /leaderboard only works in one."}},
+
+		// market
+		{name: "price", content: "/price", want: []string{usagePrice}},
+		{name: "pricehistory", content: "/pricehistory", want: []string{usageHistory}},
+		{name: "watch", content: "/watch", want: []string{"Usage:\n/watch add <below|above> <price> <item_name...>\n/watch list\n/watch remove <number>"}},
+		{name: "watch add", content: "/watch add", want: []string{usageWatchAdd}},
+		{name: "watch add item first", content: "/watch add ak redline below 12.50", want: []string{usageWatchAdd}},
+		{name: "watch list empty", content: "/watch list", want: []string{"You aren't watching anything. Add a watch with /watch add."}},
+		{name: "watch remove", content: "/watch remove", want: []string{"Usage: /watch remove <number>"}},
+		{name: "watch remove unknown", content: "/watch remove 9", want: []string{"Failed to remove watch: you have no watch number 9"}},
+		{name: "inventory", content: "/inventory", want: []string{"Usage: /inventory <profile> [appid]"}},
+		{name: "portfolio empty", content: "/portfolio", want: []string{"Your portfolio is empty. Add a purchase with /portfolio add."}},
+		{name: "portfolio add", content: "/portfolio add", want: []string{usagePortfolioAdd}},
+		{name: "portfolio add item first", content: "/portfolio add ak redline 3 12.50", want: []string{"`ak` isn't a quantity. Use a whole number like 3."}},
+		{name: "portfolio add bad price", content: "/portfolio add 3 cheap ak redline", want: []string{"`cheap` isn't a price. Use a number like 12.50."}},
+		{name: "portfolio remove unknown", content: "/portfolio remove 3", want: []string{"Failed to remove from portfolio: you have no portfolio entry number 3"}},
+		{name: "portfolio export empty", content: "/portfolio export", want: []string{"Your portfolio is empty. Add a purchase with /portfolio add."}},
+		{name: "market", content: "/market", want: []string{"Usage:\n/market defaults [game] [" + currencies + "]\n/market reset"}},
+		{name: "market defaults", content: "/market defaults", want: []string{"Your market lookups use Counter-Strike 2 prices in GBP."}},
+		{name: "market defaults set", content: "/market defaults dota2 EUR", want: []string{"Your market lookups use Dota 2 prices in EUR."}},
+		{name: "market defaults bad game", content: "/market defaults xx", want: []string{"unknown game \"xx\", use cs2, csgo, dota2, rust, tf2 or a numeric app ID"}},
+		{name: "market defaults from server", content: "/market defaults",
+			setup: []string{"/config market rust USD"}, want: []string{"Your market lookups use Rust prices in USD."}},
+		{name: "market reset", content: "/market reset", want: []string{"Your market lookups are back to Counter-Strike 2 prices in GBP."}},
+
+		// servers
+		{name: "servers list", content: "/servers list", want: []string{
+			"**Valheim** `65.21.132.169:2456` a2s, query port 2457\n__Minecraft__\n**Minecraft Vanilla** `65.21.132.169:25565` minecraft\n**Minecraft Modded: Society Sunlit Valley** `65.21.132.169:25566` minecraft\n",
+		}},
+		{name: "servers add", content: "/servers add", want: []string{usageServers}},
+		{name: "servers add bad port", content: "/servers add a b c", want: []string{"`c` isn't a port number."}},
+		{name: "servers add bad protocol", content: "/servers add a example.com 80 zz", want: []string{
+			"Failed to add server: a has unknown protocol \"zz\", use one of minecraft, a2s, tcp",
+		}},
+		{name: "servers add loopback", content: "/servers add a 127.0.0.1 22", want: []string{"Failed to add server: a's host 127.0.0.1 is a local or private address"}},
+		{name: "servers add needs permission", content: "/servers add a example.com 80", member: true, want: []string{denied}},
+		{name: "servers remove", content: "/servers remove", want: []string{"Usage: /servers remove <name...>"}},
+		{name: "servers remove unknown", content: "/servers remove nope", want: []string{"No server called `nope` is checked here. See /servers list."}},
+		{name: "servers remove in dm", content: "/servers remove nope", dm: true, want: []string{needsServer}},
+
+		// config
+		{name: "config", content: "/config", want: []string{"Usage:\n/config show\n/config channel <add|remove|clear> [channel]\n/config prefix <prefix>\n" +
+			"/config module <enable|disable> <" + strings.ReplaceAll(modules, ", ", "|") + ">\n/config locale <locale>\n/config market [game] [" + currencies + "]\n" +
+			"/config odds [sport_key] [uk|eu|us|us2|au] [h2h|spreads|totals|outrights]"}},
+		{name: "config show", content: "/config show", want: []string{"embed: Server Configuration"}},
+		{name: "config needs permission", content: "/config show", member: true, want: []string{denied}},
+		{name: "config in dm", content: "/config show", dm: true, want: []string{serverOnly}},
+		{name: "config channel", content: "/config channel", want: []string{"Usage: /config channel <add|remove|clear> [channel]"}},
+		{name: "config channel bad action", content: "/config channel bogus", want: []string{"Usage: /config channel bogus <#channel>"}},
+		{name: "config channel elsewhere", content: "/help",
+			setup: []string{"/config channel add <#999>"}, want: nil},
+		{name: "config prefix", content: "/config prefix", want: []string{"Usage: /config prefix <prefix>"}},
+		{name: "config module", content: "/config module", want: []string{"Usage: /config module <enable|disable> <" + strings.ReplaceAll(modules, ", ", "|") + ">"}},
+		{name: "config module unknown", content: "/config module enable nope", want: []string{"Unknown module. Available modules: " + modules}},
+		{name: "config locale unknown", content: "/config locale xx", want: []string{"Unknown locale. Use a Discord locale code such as en-GB, en-US, de or fr."}},
+		{name: "config market", content: "/config market", want: []string{"Usage: /config market [game] [currency]"}},
+		{name: "config market bad game", content: "/config market zz", want: []string{
+			"Failed to update market defaults: unknown game \"zz\", use cs2, csgo, dota2, rust, tf2 or a numeric app ID",
+		}},
+		{name: "config odds", content: "/config odds", want: []string{"Usage: /config odds [sport_key] [region] [market]"}},
+		{name: "config odds bad region", content: "/config odds x zz", want: []string{"unknown region \"zz\", use one of uk, eu, us, us2, au"}},
+		{name: "perm", content: "/perm", want: []string{"Usage:\n/perm grant <role|user> <target> <" + permissions + ">\n/perm revoke <role|user> <target> <" + permissions + ">\n/perm list"}},
+		{name: "perm grant", content: "/perm grant", want: []string{"Usage: /perm grant <role|user> <target> <" + permissions + ">"}},
+		{name: "perm grant unknown", content: "/perm grant role x nope", want: []string{"Unknown permission. Available permissions: manage_config, clear_messages, steam_accounts"}},
+		{name: "perm list", content: "/perm list", want: []string{"embed: Permissions"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServices(t)
+			r := registerCommands(s)
+			admin := &routertest.Session{Permissions: discordgo.PermissionAdministrator}
+			for _, content := range tt.setup {
+				if !r.Dispatch(router.Prefix, admin, routertest.GuildMessage(content)) {
+					t.Fatalf("setup %q didn't match a command", content)
+				}
+			}
+
+			session := &routertest.Session{
+				Permissions: discordgo.PermissionAdministrator,
+				Channels:    map[string]*discordgo.Channel{otherChannel: {ID: otherChannel, GuildID: "900"}},
+			}
+			if tt.member {
+				session.Permissions = 0
+			}
+			message := routertest.GuildMessage(tt.content)
+			if tt.dm {
+				message = routertest.DM(tt.content)
+			}
+			r.Dispatch(router.Prefix, session, message)
+			if got := replies(message.ChannelID, session.Sent()); !slices.Equal(got, tt.want) {
+				t.Errorf("%s replied\n%q\nwant\n%q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestEveryCommandCovered fails when a command is registered without a case
+// in TestCommands, so new commands get tests too.
+func TestEveryCommandCovered(t *testing.T) {
+	covered := []string{
+		"help", "dm", "proxy", "report", "bot-add", "bot-remove", "bot-list", "clear",
+		"odds", "predict", "leaderboard", "football", "number", "username", "string",
+		"yopmail", "mail", "inbox", "view", "del", "address",
+		"price", "pricehistory", "watch", "inventory", "portfolio", "market",
+		"servers", "config", "perm",
+	}
+	r := registerCommands(newTestServices(t))
+	for _, c := range r.Commands() {
+		if !slices.Contains(covered, c.Name) {
+			t.Errorf("/%s has no test cases", c.Name)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+
+	"discordBot/bot/ratelimit"
+	betting "discordBot/functions/betting"
+	"discordBot/functions/servercheck"
+	steammarket "discordBot/functions/steamMarket"
+)
+
+// Bot is the bot-wide configuration read from config.json at startup.
+// Set BOT_CONFIG to load it from somewhere else.
+type Bot struct {
+	// Owners are user IDs that may run every command, anywhere.
+	Owners []string `json:"owners"`
+	// DMOwnersOnly ignores DMs from anyone who isn't an owner.
+	DMOwnersOnly bool `json:"dm_owners_only"`
+	// RateLimits replaces ratelimit.DefaultConfig when set.
+	RateLimits *ratelimit.Config `json:"rate_limits,omitempty"`
+	// Market replaces steammarket.DefaultConfig when set.
+	Market *steammarket.Config `json:"market,omitempty"`
+	// Odds replaces betting.DefaultConfig when set.
+	Odds *betting.Config `json:"odds,omitempty"`
+	// Servers replaces servercheck.DefaultConfig when set.
+	Servers *servercheck.Config `json:"servers,omitempty"`
+}
+
+// LoadBot reads the bot configuration. A missing file gives the defaults.
+func LoadBot() (*Bot, error) {
+	path := os.Getenv("BOT_CONFIG")
+	if path == "" {
+		path = "config.json"
+	}
+	cfg := &Bot{}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(raw, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// IsOwner reports whether userID is one of the bot owners.
+func (b *Bot) IsOwner(userID string) bool {
+	return slices.Contains(b.Owners, userID)
+}
+
+// Limits returns the configured rate limits, or the defaults.
+func (b *Bot) Limits() ratelimit.Config {
+	if b.RateLimits == nil {
+		return ratelimit.DefaultConfig()
+	}
+	return *b.RateLimits
+}
+
+// MarketConfig returns the configured Steam Market settings, or the defaults.
+func (b *Bot) MarketConfig() steammarket.Config {
+	if b.Market == nil {
+		return steammarket.DefaultConfig()
+	}
+	return *b.Market
+}
+
+// OddsConfig returns the configured odds snapshot and alert settings, or the defaults.
+func (b *Bot) OddsConfig() betting.Config {
+	if b.Odds == nil {
+		return betting.DefaultConfig()
+	}
+	return *b.Odds
+}
+
+// ServersConfig returns the configured game server inventory, or the defaults.
+func (b *Bot) ServersConfig() servercheck.Config {
+	if b.Servers == nil {
+		return servercheck.DefaultConfig()
+	}
+	return *b.Servers
+}
@@ -0,0 +1,433 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"discordBot/bot/router"
+	betting "discordBot/functions/betting"
+	steammarket "discordBot/functions/steamMarket"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Module is the module name of the /config command itself, which can't be disabled.
+const Module = "config"
+
+// Register adds the /config and /perm commands to r. Call it after every
+// other module has registered, so the module list is complete.
+func Register(r *router.Router, guilds *Guilds) {
+	h := &handlers{guilds: guilds, modules: modules(r)}
+	r.Register(router.Command{
+		Name:        "config",
+		Module:      Module,
+		Permission:  PermManageConfig,
+		Description: "Change how the bot behaves in this server.",
+		Subcommands: []*router.Command{
+			{
+				Name:        "show",
+				Description: "Show this server's configuration.",
+				Handler:     h.show,
+			},
+			{
+				Name:        "channel",
+				Description: "Limit commands to specific channels.",
+				Args: []router.Arg{
+					{Name: "action", Description: "add, remove or clear", Required: true, Choices: []string{"add", "remove", "clear"}},
+					{Name: "channel", Description: "Channel to add or remove", Type: router.ArgChannel},
+				},
+				Handler: h.channel,
+			},
+			{
+				Name:        "prefix",
+				Description: "Set the prefix for text commands.",
+				Args:        []router.Arg{{Name: "prefix", Description: "e.g. / or !", Required: true}},
+				Handler:     h.prefix,
+			},
+			{
+				Name:        "module",
+				Description: "Switch a group of commands on or off.",
+				Args: []router.Arg{
+					{Name: "action", Description: "enable or disable", Required: true, Choices: []string{"enable", "disable"}},
+					{Name: "module", Description: "Module name", Required: true, Choices: h.modules},
+				},
+				Handler: h.module,
+			},
+			{
+				Name:        "locale",
+				Description: "Set the server language, e.g. en-GB.",
+				Args:        []router.Arg{{Name: "locale", Description: "Discord locale code", Required: true}},
+				Handler:     h.locale,
+			},
+			{
+				Name:        "market",
+				Description: "Set the server's default game and currency for /price.",
+				Args: []router.Arg{
+					{Name: "game", Description: "cs2, dota2, tf2, rust or a Steam app ID"},
+					{Name: "currency", Description: "Currency code, e.g. GBP", Choices: steammarket.CurrencyCodes()},
+				},
+				Handler: h.market,
+			},
+			{
+				Name:        "odds",
+				Description: "Set the server's default sport, region and market for /odds.",
+				Args: []router.Arg{
+					{Name: "sport_key", Description: "The Odds API sport key, e.g. soccer_epl"},
+					{Name: "region", Description: "Bookmaker region", Choices: betting.Regions},
+					{Name: "market", Description: "Betting market", Choices: betting.Markets},
+				},
+				Handler: h.odds,
+			},
+		},
+	})
+
+	grantArgs := []router.Arg{
+		{Name: "kind", Description: "Grant to a role or a single user", Required: true, Choices: []string{"role", "user"}},
+		{Name: "target", Description: "Role or user mention, or ID", Required: true},
+		{Name: "permission", Description: "Permission name", Required: true, Choices: permissionNames()},
+	}
+	r.Register(router.Command{
+		Name:        "perm",
+		Module:      Module,
+		Permission:  PermManageConfig,
+		Description: "Grant command permissions to roles and users.",
+		Subcommands: []*router.Command{
+			{
+				Name:        "grant",
+				Description: "Give a role or user a permission.",
+				Args:        grantArgs,
+				Handler:     h.grant,
+			},
+			{
+				Name:        "revoke",
+				Description: "Take a permission away from a role or user.",
+				Args:        grantArgs,
+				Handler:     h.revoke,
+			},
+			{
+				Name:        "list",
+				Description: "Show who holds which permission in this server.",
+				Handler:     h.listPerms,
+			},
+		},
+	})
+}
+
+// modules lists the distinct module names registered on r, minus config itself.
+func modules(r *router.Router) []string {
+	var out []string
+	for _, c := range r.Commands() {
+		if c.Module != "" && c.Module != Module && !slices.Contains(out, c.Module) {
+			out = append(out, c.Module)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+type handlers struct {
+	guilds  *Guilds
+	modules []string
+}
+
+func (h *handlers) show(req *router.Request) {
+	cfg := h.guilds.Get(req.GuildID)
+	channels := "all channels"
+	if len(cfg.AllowedChannels) > 0 {
+		mentions := make([]string, len(cfg.AllowedChannels))
+		for i, id := range cfg.AllowedChannels {
+			mentions[i] = "<#" + id + ">"
+		}
+		channels = strings.Join(mentions, ", ")
+	}
+	var enabled, disabled []string
+	for _, m := range h.modules {
+		if cfg.ModuleEnabled(m) {
+			enabled = append(enabled, m)
+		} else {
+			disabled = append(disabled, m)
+		}
+	}
+	locale := cfg.Locale
+	if locale == "" {
+		locale = "default"
+	}
+	appID, currency := cfg.Market()
+	odds := cfg.Odds()
+	req.ReplyEmbed(&discordgo.MessageEmbed{
+		Title: "Server Configuration",
+		Color: 0x00ffcc,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Channels", Value: channels},
+			{Name: "Prefix", Value: "`" + cfg.CommandPrefix() + "`", Inline: true},
+			{Name: "Locale", Value: locale, Inline: true},
+			{Name: "Market", Value: steammarket.GameName(appID) + ", " + currency, Inline: true},
+			{Name: "Odds", Value: odds.Sport + ", " + odds.Region + ", " + odds.Market, Inline: true},
+			{Name: "Enabled modules", Value: listOrNone(enabled)},
+			{Name: "Disabled modules", Value: listOrNone(disabled)},
+		},
+	})
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+	return strings.Join(items, ", ")
+}
+
+func (h *handlers) channel(req *router.Request) {
+	action, channelID := req.Arg(0), req.Arg(1)
+	if action != "clear" && channelID == "" {
+		req.Reply("Usage: /config channel " + action + " <#channel>")
+		return
+	}
+	err := h.guilds.Update(req.GuildID, func(cfg *Guild) error {
+		switch action {
+		case "add":
+			if !slices.Contains(cfg.AllowedChannels, channelID) {
+				cfg.AllowedChannels = append(cfg.AllowedChannels, channelID)
+			}
+		case "remove":
+			cfg.AllowedChannels = slices.DeleteFunc(cfg.AllowedChannels, func(id string) bool { return id == channelID })
+		case "clear":
+			cfg.AllowedChannels = nil
+		default:
+			return fmt.Errorf("unknown action %q, use add, remove or clear", action)
+		}
+		return nil
+	})
+	if err != nil {
+		req.Reply("Failed to update channels: " + err.Error())
+		return
+	}
+	switch action {
+	case "add":
+		req.Reply("Commands are now allowed in <#" + channelID + ">.")
+	case "remove":
+		req.Reply("Commands are no longer allowed in <#" + channelID + ">.")
+	default:
+		req.Reply("Commands are now allowed in every channel.")
+	}
+}
+
+func (h *handlers) prefix(req *router.Request) {
+	prefix := req.Arg(0)
+	if len(prefix) > 5 || strings.ContainsAny(prefix, " \t\n") {
+		req.Reply("The prefix must be 1-5 characters with no spaces.")
+		return
+	}
+	err := h.guilds.Update(req.GuildID, func(cfg *Guild) error {
+		cfg.Prefix = prefix
+		return nil
+	})
+	if err != nil {
+		req.Reply("Failed to update prefix: " + err.Error())
+		return
+	}
+	req.Reply("Text commands now start with `" + prefix + "`. Slash commands are unaffected.")
+}
+
+func (h *handlers) module(req *router.Request) {
+	action, module := req.Arg(0), strings.ToLower(req.Arg(1))
+	if !slices.Contains(h.modules, module) {
+		req.Reply("Unknown module. Available modules: " + strings.Join(h.modules, ", "))
+		return
+	}
+	err := h.guilds.Update(req.GuildID, func(cfg *Guild) error {
+		switch action {
+		case "enable":
+			cfg.DisabledModules = slices.DeleteFunc(cfg.DisabledModules, func(m string) bool { return m == module })
+		case "disable":
+			if !slices.Contains(cfg.DisabledModules, module) {
+				cfg.DisabledModules = append(cfg.DisabledModules, module)
+			}
+		default:
+			return errors.New("use enable or disable")
+		}
+		return nil
+	})
+	if err != nil {
+		req.Reply("Failed to update modules: " + err.Error())
+		return
+	}
+	req.Reply("Module `" + module + "` " + action + "d.")
+}
+
+func (h *handlers) locale(req *router.Request) {
+	locale := discordgo.Locale(req.Arg(0))
+	if _, ok := discordgo.Locales[locale]; !ok {
+		req.Reply("Unknown locale. Use a Discord locale code such as en-GB, en-US, de or fr.")
+		return
+	}
+	err := h.guilds.Update(req.GuildID, func(cfg *Guild) error {
+		cfg.Locale = string(locale)
+		return nil
+	})
+	if err != nil {
+		req.Reply("Failed to update locale: " + err.Error())
+		return
+	}
+	req.Reply("Locale set to " + discordgo.Locales[locale] + ".")
+}
+
+func (h *handlers) market(req *router.Request) {
+	game, currency := req.Arg(0), req.Arg(1)
+	if game == "" && currency == "" {
+		req.Reply("Usage: /config market [game] [currency]")
+		return
+	}
+	err := h.guilds.Update(req.GuildID, func(cfg *Guild) error {
+		if game != "" {
+			appID, err := steammarket.ParseGame(game)
+			if err != nil {
+				return err
+			}
+			cfg.MarketAppID = appID
+		}
+		if currency != "" {
+			cur, err := steammarket.LookupCurrency(currency)
+			if err != nil {
+				return err
+			}
+			cfg.MarketCurrency = cur.Code
+		}
+		return nil
+	})
+	if err != nil {
+		req.Reply("Failed to update market defaults: " + err.Error())
+		return
+	}
+	appID, currency := h.guilds.Get(req.GuildID).Market()
+	req.Reply("Market lookups in this server now default to " + steammarket.GameName(appID) + " prices in " + currency + ".")
+}
+
+func (h *handlers) odds(req *router.Request) {
+	d := betting.Defaults{Sport: strings.ToLower(req.Arg(0)), Region: strings.ToLower(req.Arg(1)), Market: strings.ToLower(req.Arg(2))}
+	if d == (betting.Defaults{}) {
+		req.Reply("Usage: /config odds [sport_key] [region] [market]")
+		return
+	}
+	if err := betting.ValidateDefaults(d); err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	err := h.guilds.Update(req.GuildID, func(cfg *Guild) error {
+		if d.Sport != "" {
+			cfg.OddsSport = d.Sport
+		}
+		if d.Region != "" {
+			cfg.OddsRegion = d.Region
+		}
+		if d.Market != "" {
+			cfg.OddsMarket = d.Market
+		}
+		return nil
+	})
+	if err != nil {
+		req.Reply("Failed to update odds defaults: " + err.Error())
+		return
+	}
+	odds := h.guilds.Get(req.GuildID).Odds()
+	req.Reply("/odds in this server now defaults to " + odds.Sport + " with " + odds.Region + " bookmakers and the " + odds.Market + " market.")
+}
+
+func (h *handlers) grant(req *router.Request) {
+	h.setGrant(req, true)
+}
+
+func (h *handlers) revoke(req *router.Request) {
+	h.setGrant(req, false)
+}
+
+func (h *handlers) setGrant(req *router.Request, grant bool) {
+	kind, target, perm := req.Arg(0), mentionID(req.Arg(1)), req.Arg(2)
+	if !slices.Contains(Permissions, router.Permission(perm)) {
+		req.Reply("Unknown permission. Available permissions: " + strings.Join(permissionNames(), ", "))
+		return
+	}
+	if kind != "role" && kind != "user" {
+		req.Reply("Use `role` or `user`.")
+		return
+	}
+	err := h.guilds.Update(req.GuildID, func(cfg *Guild) error {
+		grants := &cfg.UserPermissions
+		if kind == "role" {
+			grants = &cfg.RolePermissions
+		}
+		if *grants == nil {
+			*grants = make(map[string][]string)
+		}
+		held := slices.DeleteFunc((*grants)[target], func(p string) bool { return p == perm })
+		if grant {
+			held = append(held, perm)
+		}
+		if len(held) == 0 {
+			delete(*grants, target)
+		} else {
+			(*grants)[target] = held
+		}
+		return nil
+	})
+	if err != nil {
+		req.Reply("Failed to update permissions: " + err.Error())
+		return
+	}
+	if grant {
+		req.Reply("Granted `" + perm + "` to " + mention(kind, target) + ".")
+	} else {
+		req.Reply("Revoked `" + perm + "` from " + mention(kind, target) + ".")
+	}
+}
+
+func (h *handlers) listPerms(req *router.Request) {
+	cfg := h.guilds.Get(req.GuildID)
+	var fields []*discordgo.MessageEmbedField
+	for _, perm := range Permissions {
+		var holders []string
+		for id, held := range cfg.RolePermissions {
+			if slices.Contains(held, string(perm)) {
+				holders = append(holders, mention("role", id))
+			}
+		}
+		for id, held := range cfg.UserPermissions {
+			if slices.Contains(held, string(perm)) {
+				holders = append(holders, mention("user", id))
+			}
+		}
+		sort.Strings(holders)
+		fields = append(fields, &discordgo.MessageEmbedField{Name: string(perm), Value: listOrNone(holders)})
+	}
+	req.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "Permissions",
+		Description: "Server admins hold every permission.",
+		Color:       0x00ffcc,
+		Fields:      fields,
+	})
+}
+
+func permissionNames() []string {
+	names := make([]string, len(Permissions))
+	for i, p := range Permissions {
+		names[i] = string(p)
+	}
+	return names
+}
+
+// mentionID strips Discord mention syntax (<@id>, <@!id>, <@&id>) down to the ID.
+func mentionID(s string) string {
+	s = strings.TrimPrefix(s, "<@")
+	s = strings.TrimPrefix(s, "&")
+	s = strings.TrimPrefix(s, "!")
+	return strings.TrimSuffix(s, ">")
+}
+
+func mention(kind, id string) string {
+	if kind == "role" {
+		return "<@&" + id + ">"
+	}
+	return "<@" + id + ">"
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"slices"
+
+	"discordBot/bot/router"
+	betting "discordBot/functions/betting"
+	steammarket "discordBot/functions/steamMarket"
+	"discordBot/store"
+)
+
+const guildFile = "guilds.json"
+
+// Guild is the per-server configuration admins change with /config.
+type Guild struct {
+	// AllowedChannels limits commands to these channels. Empty means every channel.
+	AllowedChannels []string `json:"allowed_channels,omitempty"`
+	Prefix          string   `json:"prefix,omitempty"`
+	// DisabledModules lists modules switched off in this server. Modules are on by default.
+	DisabledModules []string `json:"disabled_modules,omitempty"`
+	Locale          string   `json:"locale,omitempty"`
+	// RolePermissions and UserPermissions map a role or user ID to the
+	// permissions granted with /perm.
+	RolePermissions map[string][]string `json:"role_permissions,omitempty"`
+	UserPermissions map[string][]string `json:"user_permissions,omitempty"`
+	// MarketAppID and MarketCurrency are the server's default game and
+	// currency for Steam Market lookups, set with /config market.
+	MarketAppID    int    `json:"market_appid,omitempty"`
+	MarketCurrency string `json:"market_currency,omitempty"`
+	// OddsSport, OddsRegion and OddsMarket are the server's defaults for
+	// /odds, set with /config odds.
+	OddsSport  string `json:"odds_sport,omitempty"`
+	OddsRegion string `json:"odds_region,omitempty"`
+	OddsMarket string `json:"odds_market,omitempty"`
+}
+
+// CommandPrefix returns the prefix text commands must start with.
+func (g Guild) CommandPrefix() string {
+	if g.Prefix == "" {
+		return router.Prefix
+	}
+	return g.Prefix
+}
+
+// ChannelAllowed reports whether commands may be used in channelID.
+func (g Guild) ChannelAllowed(channelID string) bool {
+	return len(g.AllowedChannels) == 0 || slices.Contains(g.AllowedChannels, channelID)
+}
+
+// ModuleEnabled reports whether commands of module may be used.
+func (g Guild) ModuleEnabled(module string) bool {
+	return !slices.Contains(g.DisabledModules, module)
+}
+
+// Market returns the server's default game and currency for market lookups,
+// falling back to the bot-wide defaults.
+func (g Guild) Market() (appID int, currency string) {
+	appID, currency = g.MarketAppID, g.MarketCurrency
+	if appID == 0 {
+		appID = steammarket.DefaultAppID
+	}
+	if currency == "" {
+		currency = steammarket.DefaultCurrencyCode
+	}
+	return appID, currency
+}
+
+// Odds returns the server's /odds defaults, falling back to the bot-wide ones.
+func (g Guild) Odds() betting.Defaults {
+	d := betting.Defaults{Sport: g.OddsSport, Region: g.OddsRegion, Market: g.OddsMarket}
+	if d.Sport == "" {
+		d.Sport = betting.DefaultSport
+	}
+	if d.Region == "" {
+		d.Region = betting.DefaultRegion
+	}
+	if d.Market == "" {
+		d.Market = betting.DefaultMarket
+	}
+	return d
+}
+
+// Guilds stores the configuration of every server the bot is in.
+type Guilds struct {
+	file *store.File[map[string]*Guild]
+}
+
+// OpenGuilds loads guilds.json from the data directory.
+func OpenGuilds() (*Guilds, error) {
+	f, err := store.Open(guildFile, map[string]*Guild{})
+	if err != nil {
+		return nil, err
+	}
+	return &Guilds{file: f}, nil
+}
+
+// Get returns a copy of the configuration for guildID. DMs (an empty
+// guildID) always get the defaults.
+func (g *Guilds) Get(guildID string) Guild {
+	var out Guild
+	if guildID == "" {
+		return out
+	}
+	g.file.View(func(all *map[string]*Guild) {
+		if cfg, ok := (*all)[guildID]; ok {
+			out = *cfg
+			out.AllowedChannels = slices.Clone(cfg.AllowedChannels)
+			out.DisabledModules = slices.Clone(cfg.DisabledModules)
+			out.RolePermissions = cloneGrants(cfg.RolePermissions)
+			out.UserPermissions = cloneGrants(cfg.UserPermissions)
+		}
+	})
+	return out
+}
+
+// Update changes the configuration for guildID and saves it.
+func (g *Guilds) Update(guildID string, fn func(cfg *Guild) error) error {
+	return g.file.Update(func(all *map[string]*Guild) error {
+		cfg, ok := (*all)[guildID]
+		if !ok {
+			cfg = &Guild{}
+			(*all)[guildID] = cfg
+		}
+		return fn(cfg)
+	})
+}
+
+func cloneGrants(grants map[string][]string) map[string][]string {
+	if grants == nil {
+		return nil
+	}
+	out := make(map[string][]string, len(grants))
+	for id, perms := range grants {
+		out[id] = slices.Clone(perms)
+	}
+	return out
+}
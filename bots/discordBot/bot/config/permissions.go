@@ -0,0 +1,92 @@
+package config
+
+import (
+	"slices"
+
+	"discordBot/bot/router"
+	"discordBot/util"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Permissions commands can declare. Server admins hold all of them.
+const (
+	PermManageConfig  router.Permission = "manage_config"
+	PermClearMessages router.Permission = "clear_messages"
+	PermSteamAccounts router.Permission = "steam_accounts"
+)
+
+// Permissions lists every permission that can be granted with /perm.
+var Permissions = []router.Permission{PermManageConfig, PermClearMessages, PermSteamAccounts}
+
+var (
+	errDMOwnersOnly = router.Deny("DMs with this bot are limited to its owners.")
+	errDMNeedsOwner = router.Deny("This command needs a permission that can only be used in a server, or by a bot owner.")
+	errDenied       = router.Deny("You don't have permission to use this command.")
+)
+
+// PermissionCheck returns a router check that enforces command permissions:
+// bot owners may do anything, server admins may do anything in their server,
+// and everyone else needs the permission granted to them or one of their roles.
+func PermissionCheck(bot *Bot, guilds *Guilds) router.Check {
+	logger := util.LoggerInit("BOT", "Permissions")
+	return func(req *router.Request) error {
+		if bot.IsOwner(req.Author.ID) {
+			return nil
+		}
+		perm := req.Command.Permission
+
+		var err error
+		switch {
+		case req.GuildID == "" && bot.DMOwnersOnly:
+			err = errDMOwnersOnly
+		case perm == "":
+			return nil
+		case req.GuildID == "":
+			err = errDMNeedsOwner
+		case isGuildAdmin(req) || Granted(guilds.Get(req.GuildID), req, perm):
+			return nil
+		default:
+			err = errDenied
+		}
+		logger.Warn("Command denied",
+			"user", req.Author.ID,
+			"guild", req.GuildID,
+			"channel", req.ChannelID,
+			"command", req.Command.FullName(),
+			"permission", perm,
+		)
+		return err
+	}
+}
+
+// Granted reports whether the caller or one of their roles was given perm.
+func Granted(cfg Guild, req *router.Request, perm router.Permission) bool {
+	if slices.Contains(cfg.UserPermissions[req.Author.ID], string(perm)) {
+		return true
+	}
+	if req.Member == nil {
+		return false
+	}
+	for _, role := range req.Member.Roles {
+		if slices.Contains(cfg.RolePermissions[role], string(perm)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isGuildAdmin reports whether the caller can manage the server.
+func isGuildAdmin(req *router.Request) bool {
+	var perms int64
+	if req.Interaction != nil && req.Member != nil {
+		perms = req.Member.Permissions
+	} else {
+		p, err := req.Session.UserChannelPermissions(req.Author.ID, req.ChannelID)
+		if err != nil {
+			return false
+		}
+		perms = p
+	}
+	return perms&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0
+}
@@ -1,377 +1,210 @@
 package bot
 
 import (
-	"encoding/json"
-	"html"
-	"regexp"
-	"strconv"
 	"strings"
 
-	betting "discordBot/functions/betting"
+	"discordBot/bot/config"
+	"discordBot/bot/ratelimit"
+	"discordBot/bot/router"
 	clear "discordBot/functions/clearbotmsg"
 	"discordBot/functions/generators"
 	"discordBot/functions/help"
 	getproxy "discordBot/functions/proxy"
 	"discordBot/functions/tempmail"
 	"discordBot/util"
-
-	"github.com/bwmarrin/discordgo"
 )
 
 const (
-	CS_REPORTER_BINARY     = "../bin/cs_reporter"
-	SERVICE_CHECKER_BINARY = "../bin/service_checker"
+	CS_REPORTER_BINARY = "./bin/csreport"
 )
 
-// Handler function signatures
-func HandleDM(server *discordgo.Session, message *discordgo.MessageCreate) {
-	userID := message.Author.ID
-	dmChannel, err := server.UserChannelCreate(userID)
-	if err != nil {
-		server.ChannelMessageSend(message.ChannelID, "Failed to open DM: "+err.Error())
-		return
-	}
-	server.ChannelMessageSend(dmChannel.ID, "Hello! This is your DM with the bot. You can interact with me here. \n Run /help to see available commands.")
-	server.ChannelMessageSend(message.ChannelID, "I've sent you a DM!")
+// botListPageSize is how many accounts each page of /bot-list shows.
+const botListPageSize = 15
+
+// registerCommands builds the router with every command the bot answers to.
+func registerCommands(s *services) *router.Router {
+	r := router.New()
+
+	r.Register(router.Command{
+		Name:    "help",
+		Module:  "general",
+		Aliases: []string{"commands"},
+		Args: []router.Arg{{
+			Name:        "topic",
+			Description: "Which group of commands to show",
+			Choices:     []string{"steam", "mail", "util", "bets", "generators", "market"},
+		}},
+		Description: "Displays the command list, or the commands for one topic.",
+		Handler:     HandleHelp,
+	})
+	r.Register(router.Command{
+		Name:        "dm",
+		Module:      "general",
+		Description: "Opens a DM with the bot.",
+		Handler:     HandleDM,
+	})
+	r.Register(router.Command{
+		Name:   "proxy",
+		Module: "utility",
+		Args: []router.Arg{{
+			Name:        "type",
+			Description: "Proxy protocol (default http)",
+			Choices:     []string{"http", "https", "socks5"},
+		}},
+		Description: "Sends a tested, working proxy (http, https or socks5).",
+		Handler:     HandleProxy,
+	})
+	r.Register(router.Command{
+		Name:       "report",
+		Module:     "steam",
+		Permission: config.PermSteamAccounts,
+		Args: []router.Arg{
+			{Name: "uid", Description: "Steam64 ID or profile URL", Required: true},
+			{Name: "amount", Description: "Number of reports (default 1)", Type: router.ArgInteger, Min: 1},
+		},
+		Usage:       "Usage: /report <uid> <amount>",
+		Description: "Report a player using the specified number of bots (default 1).",
+		Handler:     HandleReport,
+	})
+	r.Register(router.Command{
+		Name:       "bot-add",
+		Module:     "steam",
+		Permission: config.PermSteamAccounts,
+		Args: []router.Arg{
+			{Name: "username", Description: "Steam account username", Required: true},
+			{Name: "password", Description: "Steam account password", Required: true},
+		},
+		Description: "Add a Steam bot account for reporting.",
+		Handler:     HandleBotAdd,
+	})
+	r.Register(router.Command{
+		Name:        "bot-remove",
+		Module:      "steam",
+		Permission:  config.PermSteamAccounts,
+		Aliases:     []string{"bot-del"},
+		Args:        []router.Arg{{Name: "username", Description: "Steam account username", Required: true}},
+		Description: "Remove a Steam bot account by username.",
+		Handler:     HandleBotRemove,
+	})
+	r.Register(router.Command{
+		Name:        "bot-list",
+		Module:      "steam",
+		Permission:  config.PermSteamAccounts,
+		Description: "List all added Steam bot accounts.",
+		Handler:     HandleBotList,
+	})
+
+	clear.Register(r)
+	s.odds.Register(r, config.PermManageConfig)
+	generators.Register(r)
+	tempmail.Register(r)
+	s.market.Register(r)
+	s.servers.Register(r, config.PermManageConfig)
+	config.Register(r, s.guilds)
+
+	r.Use(guildCheck(s.guilds))
+	r.Use(config.PermissionCheck(s.bot, s.guilds))
+	r.Use(ratelimit.Check(ratelimit.NewLimiter(ratelimit.SystemClock), s.bot.Limits()))
+	return r
 }
-func HandleHelp(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	// chop the string get the argument and call the steam help function
-	content := message.Content
-	switch {
-	case strings.HasPrefix(content, "/help steam"), strings.HasPrefix(content, "help steam"):
-		help.DisplayHelpSteam(channelID, server, message)
-	case strings.HasPrefix(content, "/help mail"), strings.HasPrefix(content, "help mail"):
-		help.DisplayHelpMail(channelID, server, message)
-	case strings.HasPrefix(content, "/help util"), strings.HasPrefix(content, "help utility"):
-		help.DisplayUtilityHelp(channelID, server, message)
-	case strings.HasPrefix(content, "/help bets"), strings.HasPrefix(content, "help betting"):
-		help.DisplayBettingHelp(channelID, server, message)
-	case strings.HasPrefix(content, "/help generators"), strings.HasPrefix(content, "help generators"):
-		help.DisplayHelpGenerators(channelID, server, message)
+
+func HandleDM(req *router.Request) {
+	dmChannel, err := req.Session.UserChannelCreate(req.Author.ID)
+	if err != nil {
+		req.Reply("Failed to open DM: " + err.Error())
+		return
+	}
+	req.Session.ChannelMessageSend(dmChannel.ID, "Hello! This is your DM with the bot. You can interact with me here. \n Run /help to see available commands.")
+	req.Reply("I've sent you a DM!")
+}
+func HandleHelp(req *router.Request) {
+	switch req.Arg(0) {
+	case "steam":
+		help.DisplayHelpSteam(req)
+	case "mail":
+		help.DisplayHelpMail(req)
+	case "util", "utility":
+		help.DisplayUtilityHelp(req)
+	case "bets", "betting":
+		help.DisplayBettingHelp(req)
+	case "generators":
+		help.DisplayHelpGenerators(req)
+	case "market":
+		help.DisplayHelpMarket(req)
 	default:
-		help.DisplayHelp(channelID, server, message)
+		help.DisplayHelp(req)
 	}
 }
-func HandleProxy(server *discordgo.Session, message *discordgo.MessageCreate) {
-	parts := util.SplitArgs(message.Content)
+func HandleProxy(req *router.Request) {
 	proxyType := "http" // default
-	if len(parts) > 1 {
-		switch parts[1] {
+	if len(req.Args) > 0 {
+		switch req.Args[0] {
 		case "http", "https", "socks5":
-			proxyType = parts[1]
+			proxyType = req.Args[0]
 		default:
-			server.ChannelMessageSend(message.ChannelID, "Invalid proxy type. Use: http, https, or socks5")
+			req.Reply("Invalid proxy type. Use: http, https, or socks5")
 			return
 		}
 	}
-	proxies := getproxy.ProxyHandler(proxyType)
+	proxies := getproxy.ProxyHandler(req.Context(), proxyType)
 	for _, proxy := range proxies {
-		server.ChannelMessageSend(message.ChannelID, proxy)
+		req.Reply(proxy)
 	}
 }
-func HandleClear(server *discordgo.Session, message *discordgo.MessageCreate) {
-	userID := message.Author.ID
-	channelID := message.ChannelID
-	v := clear.ClearBotMessages(userID, channelID, server, message)
-	if !v {
-		server.ChannelMessageSend(message.ChannelID, "failed to clear messages!")
+func HandleReport(req *router.Request) {
+	uid := req.Arg(0)
+	amount := req.Arg(1)
+	if amount == "" {
+		req.Reply("Report started for: \n (uid: " + uid + ")")
+		amount = "1"
+	} else {
+		req.Reply(amount + " Reports started for: \n (uid: " + uid + ")")
 	}
-}
-func HandleFootball(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	err := betting.MatchOdds(server, message)
+	output, err := util.ExecBinary(req.Context(), CS_REPORTER_BINARY, uid, amount)
 	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to retrieve upcoming matches! ")
-	}
-}
-func HandleReport(server *discordgo.Session, message *discordgo.MessageCreate) {
-	parts := util.SplitArgs(message.Content)
-	if len(parts) < 2 {
-		server.ChannelMessageSend(message.ChannelID, "Usage: /report <uid> <amount>")
-		return
-	}
-	uid := parts[1]
-	if uid == "" {
-		server.ChannelMessageSend(message.ChannelID, "you did not provide a steam64 ID or a valid profile URL")
+		req.Reply("Failed to send reports!")
 		return
 	}
-	if len(parts) >= 3 {
-		amount := parts[2]
-		server.ChannelMessageSend(message.ChannelID, amount+" Reports started for: \n (uid: "+uid+")")
-		util.ExecBinary(uid, amount)
-	} else {
-		server.ChannelMessageSend(message.ChannelID, "Report started for: \n (uid: "+uid+")")
-		util.ExecBinary(uid, "1")
-	}
+	req.Reply("\n" + output)
+	req.Reply(amount + " Reports sent for: \n (uid: " + uid + ")")
 }
-func HandleBotAdd(server *discordgo.Session, message *discordgo.MessageCreate) {
-	parts := util.SplitArgs(message.Content)
-	if len(parts) < 3 {
-		server.ChannelMessageSend(message.ChannelID, "Usage: /bot-add <username> <password>")
-		return
-	}
-	username := parts[1]
-	password := parts[2]
-	if username == "" || password == "" {
-		server.ChannelMessageSend(message.ChannelID, "you did not provide a valid username or password")
-		return
-	}
+func HandleBotAdd(req *router.Request) {
 	command := "add"
-	args := []string{username, password}
-	output, err := util.ExecBinary(CS_REPORTER_BINARY, command, args...)
+	args := []string{req.Arg(0), req.Arg(1)}
+	output, err := util.ExecBinary(req.Context(), CS_REPORTER_BINARY, command, args...)
 	if err != nil {
-		server.ChannelMessageSend(message.ChannelID, "Failed to add bot account!")
+		req.Reply("Failed to add bot account!")
 	} else {
-		server.ChannelMessageSend(message.ChannelID, "\n"+output)
+		req.Reply("\n" + output)
 	}
 }
-func HandleBotRemove(server *discordgo.Session, message *discordgo.MessageCreate) {
-	parts := util.SplitArgs(message.Content)
-	if len(parts) < 2 {
-		server.ChannelMessageSend(message.ChannelID, "Usage: /bot-remove <username>")
-		return
-	}
-	username := parts[1]
-	if username == "" {
-		server.ChannelMessageSend(message.ChannelID, "you did not provide a valid username")
-		return
-	}
+func HandleBotRemove(req *router.Request) {
 	command := "bot-remove"
-	args := []string{username}
-	output, err := util.ExecBinary(CS_REPORTER_BINARY, command, args...)
+	args := []string{req.Arg(0)}
+	output, err := util.ExecBinary(req.Context(), CS_REPORTER_BINARY, command, args...)
 	if err != nil {
-		server.ChannelMessageSend(message.ChannelID, "Failed to remove bot account!")
+		req.Reply("Failed to remove bot account!")
 	} else {
-		server.ChannelMessageSend(message.ChannelID, "\n"+output)
+		req.Reply("\n" + output)
 	}
 }
-func HandleBotList(server *discordgo.Session, message *discordgo.MessageCreate) {
+func HandleBotList(req *router.Request) {
 	command := "bot-list"
 	args := []string{}
-	output, err := util.ExecBinary(CS_REPORTER_BINARY, command, args...)
-	if err != nil {
-		server.ChannelMessageSend(message.ChannelID, "Failed to list bot accounts!")
-	} else {
-		server.ChannelMessageSend(message.ChannelID, "\n"+"```"+output+"```")
-	}
-}
-func HandleNumber(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	parts := strings.Split(message.Content, " ")
-	if len(parts) != 2 {
-		server.ChannelMessageSend(channelID, "Please provide a valid length for the random number. Example: /number 5")
-		return
-	}
-	input := parts[1]
-	length, err := strconv.Atoi(input)
-	if err != nil || length <= 0 {
-		server.ChannelMessageSend(channelID, "Please provide a valid positive integer for the length.")
-		return
-	}
-	if length > 18 {
-		server.ChannelMessageSend(channelID, "Please provide a number length between 1 and 18.")
-		return
-	}
-	randomNumber := generators.GenerateRandomNumber(input)
-	server.ChannelMessageSend(channelID, "```Generated Random Number: "+randomNumber+"```")
-}
-func HandleUsername(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	parts := strings.Split(message.Content, " ")
-	if len(parts) != 2 {
-		server.ChannelMessageSend(channelID, "Please provide a valid input for the username. Example: /username JohnDoe")
-		return
-	}
-	input := parts[1]
-	username := generators.GenerateUsername(input)
-	server.ChannelMessageSend(channelID, "```Generated Username: "+username+"```")
-}
-func HandleString(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	parts := strings.Split(message.Content, " ")
-	if len(parts) != 2 {
-		server.ChannelMessageSend(channelID, "Please provide a valid length for the random string. Example: /string 10")
-		return
-	}
-	length, err := strconv.Atoi(parts[1])
-	if err != nil || length <= 0 {
-		server.ChannelMessageSend(channelID, "Please provide a valid positive integer for the length.")
-		return
-	}
-	randomString := generators.GenerateRandomString(length)
-	server.ChannelMessageSend(channelID, "```Generated Random String: "+randomString+"```")
-}
-func HandleYopmail(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	email, domains, err := tempmail.GetRandomYopmail()
-	parts := strings.Split(email, "'")
-	prefix := ""
-	if len(parts) > 1 {
-		prefix = parts[1]
-	}
-	url := "https://yopmail.com/en/inbox?login=" + prefix
-	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to generate random email.")
-	} else {
-		server.ChannelMessageSend(channelID, "```Email: "+prefix+"\nInbox: "+url+"\n"+"Alternate Domains:\n"+domains+"```")
-	}
-}
-func HandleMail(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	email, sidToken, err := tempmail.GetRandomGuerrillaEmail()
+	output, err := util.ExecBinary(req.Context(), CS_REPORTER_BINARY, command, args...)
 	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to generate random guerrilla email.")
-	} else {
-		server.ChannelMessageSend(channelID, "```Email: "+email+"\nInbox Token: "+sidToken+"\n *Keep your token safe to access your inbox!*```")
-	}
-}
-func HandleInbox(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	parts := util.SplitArgs(message.Content)
-	if len(parts) < 2 {
-		server.ChannelMessageSend(message.ChannelID, "Usage: /inbox <sid_token>")
-		return
-	}
-	sidToken := parts[1]
-	if sidToken == "" {
-		server.ChannelMessageSend(message.ChannelID, "you did not provide a valid sid_token")
-		return
-	}
-	output, err := tempmail.GetGuerrillaInboxRaw(sidToken)
-	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to get inbox: "+err.Error())
-		return
-	}
-	var resp tempmail.GuerrillaInboxResponse
-	err = json.Unmarshal([]byte(output), &resp)
-	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to parse inbox JSON: "+err.Error())
-		return
-	}
-	if len(resp.List) == 0 {
-		server.ChannelMessageSend(channelID, "No emails found in inbox.")
-		return
-	}
-	var msg strings.Builder
-	msg.WriteString("***inbox:***\n")
-	for _, mail := range resp.List {
-		msg.WriteString("```MailID: " + mail.MailID +
-			" | From: " + mail.MailFrom +
-			" | Subject: " + mail.MailSubject + "```\n")
-	}
-	server.ChannelMessageSend(channelID, msg.String())
-}
-func HandleView(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	parts := util.SplitArgs(message.Content)
-	if len(parts) < 3 {
-		server.ChannelMessageSend(message.ChannelID, "Usage: /view <sid_token> <mail_id>")
-		return
-	}
-	sidToken := parts[1]
-	mailID := parts[2]
-	if sidToken == "" || mailID == "" {
-		server.ChannelMessageSend(message.ChannelID, "you did not provide a valid sid_token or mail_id")
-		return
-	}
-	output, err := tempmail.GetGuerrillaMailContent(sidToken, mailID)
-	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to get email content: "+err.Error())
-		return
-	}
-
-	// Check for boolean or error response
-	var boolCheck bool
-	if err := json.Unmarshal([]byte(output), &boolCheck); err == nil {
-		server.ChannelMessageSend(channelID, "No email found or invalid response from API.")
+		req.Reply("Failed to list bot accounts!")
 		return
 	}
-
-	// Try to unmarshal as email content
-	var emailContent struct {
-		MailID      string `json:"mail_id"`
-		MailFrom    string `json:"mail_from"`
-		MailSubject string `json:"mail_subject"`
-		MailExcerpt string `json:"mail_excerpt"`
-		MailBody    string `json:"mail_body"`
-	}
-	err = json.Unmarshal([]byte(output), &emailContent)
-	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to parse email content JSON: "+err.Error())
-		return
-	}
-	body := emailContent.MailBody
-	// Replace <br> and <br/> with newlines
-	body = regexp.MustCompile(`(?i)<br\s*/?>`).ReplaceAllString(body, "\n")
-	// Remove all other HTML tags
-	body = regexp.MustCompile(`(?s)<.*?>`).ReplaceAllString(body, "")
-	// Decode HTML entities
-	body = html.UnescapeString(body)
-
-	// Remove empty lines from the body
-	lines := strings.Split(body, "\n")
-	var nonEmptyLines []string
-	for _, line := range lines {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
 		if strings.TrimSpace(line) != "" {
-			nonEmptyLines = append(nonEmptyLines, line)
+			lines = append(lines, line)
 		}
 	}
-	cleanBody := strings.Join(nonEmptyLines, "\n")
-
-	msg := "```***Email Content:***\n"
-	msg += "From: " + emailContent.MailFrom + "\n"
-	msg += "Subject: " + emailContent.MailSubject + "\n"
-	msg += "Body:\n" + cleanBody + "```"
-	server.ChannelMessageSend(channelID, msg)
-}
-func HandleDel(server *discordgo.Session, message *discordgo.MessageCreate) {
-	channelID := message.ChannelID
-	parts := util.SplitArgs(message.Content)
-	if len(parts) < 3 {
-		server.ChannelMessageSend(message.ChannelID, "Usage: /del <sid_token> <mail_id>")
-		return
-	}
-	mailID := parts[1]
-	sidToken := parts[2]
-	if sidToken == "" || mailID == "" {
-		server.ChannelMessageSend(message.ChannelID, "you did not provide a valid sid_token or mail_id")
-		return
-	}
-	output, err := tempmail.DeleteGuerrillaMail(mailID, sidToken)
-	if err != nil {
-		server.ChannelMessageSend(channelID, "Failed to delete email: "+err.Error())
+	if len(lines) == 0 {
+		req.Reply("No bot accounts added.")
 		return
 	}
-	// Respond with 'deleted' if the API response is a valid JSON array or object (success)
-	trimmed := strings.TrimSpace(output)
-	if trimmed == "" {
-		server.ChannelMessageSend(channelID, "```deleted```")
-		return
-	}
-	var resp interface{}
-	err = json.Unmarshal([]byte(trimmed), &resp)
-	if err == nil {
-		server.ChannelMessageSend(channelID, "```deleted```")
-	} else {
-		server.ChannelMessageSend(channelID, "Delete API returned: "+trimmed)
-	}
-}
-func HandleAddress(server *discordgo.Session, message *discordgo.MessageCreate) {
-	parts := util.SplitArgs(message.Content)
-	if len(parts) < 2 {
-		server.ChannelMessageSend(message.ChannelID, "Usage: /address <email_address>")
-		return
-	}
-	emailAddress := parts[1]
-	if emailAddress == "" {
-		server.ChannelMessageSend(message.ChannelID, "you did not provide a valid email address")
-		return
-	}
-	email_addr, err := tempmail.GetGuerrillaEmailAddress(emailAddress, "en")
-	if err != nil {
-		server.ChannelMessageSend(message.ChannelID, "Failed to get email address!")
-	} else {
-		server.ChannelMessageSend(message.ChannelID, "```\nEmail: "+email_addr+"```")
-	}
+	req.ReplyPages(router.Paginate("Bot Accounts", lines, botListPageSize))
 }
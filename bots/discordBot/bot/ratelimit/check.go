@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"discordBot/bot/router"
+)
+
+// Scope says who shares a command's bucket.
+type Scope string
+
+const (
+	ScopeUser   Scope = "user"
+	ScopeGuild  Scope = "guild"
+	ScopeGlobal Scope = "global"
+)
+
+// CommandLimit is the limit for one command and who it applies to.
+// Scope defaults to each user getting their own bucket.
+type CommandLimit struct {
+	Limit
+	Scope Scope `json:"scope,omitempty"`
+}
+
+// Config is the "rate_limits" section of the bot config.
+type Config struct {
+	// User and Guild cap how many commands of any kind one user, or one server, can run.
+	User  Limit `json:"user"`
+	Guild Limit `json:"guild"`
+	// Commands holds extra limits by command name, e.g. "price" or "config show".
+	Commands map[string]CommandLimit `json:"commands"`
+}
+
+// DefaultConfig protects the commands that hit paid or rate-limited APIs or
+// run the csreport binary.
+func DefaultConfig() Config {
+	return Config{
+		User:  Limit{Every: Duration(2 * time.Second), Burst: 5},
+		Guild: Limit{Every: Duration(time.Second), Burst: 20},
+		Commands: map[string]CommandLimit{
+			"price":         {Limit: Limit{Every: Duration(10 * time.Second), Burst: 3}},
+			"pricehistory":  {Limit: Limit{Every: Duration(10 * time.Second), Burst: 3}},
+			"inventory":     {Limit: Limit{Every: Duration(time.Minute), Burst: 1}},
+			"report":        {Limit: Limit{Every: Duration(5 * time.Minute), Burst: 1}, Scope: ScopeGuild},
+			"bot-add":       {Limit: Limit{Every: Duration(30 * time.Second), Burst: 2}},
+			"bot-remove":    {Limit: Limit{Every: Duration(30 * time.Second), Burst: 2}},
+			"bot-list":      {Limit: Limit{Every: Duration(30 * time.Second), Burst: 2}},
+			"football":      {Limit: Limit{Every: Duration(time.Minute), Burst: 1}, Scope: ScopeGuild},
+			"odds":          {Limit: Limit{Every: Duration(time.Minute), Burst: 1}, Scope: ScopeGuild},
+			"odds show":     {Limit: Limit{Every: Duration(time.Minute), Burst: 1}, Scope: ScopeGuild},
+			"odds arb":      {Limit: Limit{Every: Duration(time.Minute), Burst: 1}, Scope: ScopeGuild},
+			"odds value":    {Limit: Limit{Every: Duration(time.Minute), Burst: 1}, Scope: ScopeGuild},
+			"predict":       {Limit: Limit{Every: Duration(10 * time.Second), Burst: 3}},
+			"predict place": {Limit: Limit{Every: Duration(10 * time.Second), Burst: 3}},
+			"servers":       {Limit: Limit{Every: Duration(30 * time.Second), Burst: 1}, Scope: ScopeGuild},
+			"servers check": {Limit: Limit{Every: Duration(30 * time.Second), Burst: 1}, Scope: ScopeGuild},
+		},
+	}
+}
+
+// commandLimit finds the limit for cmd by its full name. A limit on a command
+// doesn't cover its subcommands, which mostly don't call the API the limit
+// protects.
+func (c Config) commandLimit(cmd *router.Command) (CommandLimit, bool) {
+	l, ok := c.Commands[cmd.FullName()]
+	return l, ok
+}
+
+// Keys lists the buckets a request draws from.
+func (c Config) Keys(req *router.Request) []Key {
+	keys := []Key{{Name: "user:" + req.Author.ID, Limit: c.User}}
+	if req.GuildID != "" {
+		keys = append(keys, Key{Name: "guild:" + req.GuildID, Limit: c.Guild})
+	}
+	if l, ok := c.commandLimit(req.Command); ok {
+		name := "cmd:" + req.Command.FullName() + ":"
+		switch l.Scope {
+		case ScopeGlobal:
+			name += "global"
+		case ScopeGuild:
+			// DMs have no server, so they share the DM user's own bucket.
+			if req.GuildID != "" {
+				name += "guild:" + req.GuildID
+			} else {
+				name += "user:" + req.Author.ID
+			}
+		default:
+			name += "user:" + req.Author.ID
+		}
+		keys = append(keys, Key{Name: name, Limit: l.Limit})
+	}
+	return keys
+}
+
+// Check returns a router check that refuses commands once a bucket is empty.
+func Check(limiter *Limiter, cfg Config) router.Check {
+	return func(req *router.Request) error {
+		ok, retry := limiter.Allow(cfg.Keys(req)...)
+		if ok {
+			return nil
+		}
+		return router.Deny(fmt.Sprintf("Slow down! Try `%s%s` again in %ds.",
+			req.Prefix(), req.Command.FullName(), int(math.Ceil(retry.Seconds()))))
+	}
+}
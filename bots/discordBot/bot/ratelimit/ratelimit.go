@@ -0,0 +1,158 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Clock tells the limiter the time, so tests can move it by hand.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the real wall clock.
+var SystemClock Clock = systemClock{}
+
+// Duration is a time.Duration written as "10s" or "1m30s" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"10s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Limit is a token bucket: Burst calls straight away, then one more every Every.
+type Limit struct {
+	Every Duration `json:"every"`
+	Burst int      `json:"burst"`
+}
+
+// Enabled reports whether l actually limits anything.
+func (l Limit) Enabled() bool {
+	return l.Every > 0 && l.Burst > 0
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// refill tops the bucket up for the time passed since it was last touched.
+func (b *bucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last)
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+float64(elapsed)/float64(b.limit.Every))
+		b.last = now
+	}
+}
+
+// wait is how long until the bucket has a whole token.
+func (b *bucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) * float64(b.limit.Every))
+}
+
+// Key names one bucket and the limit that applies to it.
+type Key struct {
+	Name  string
+	Limit Limit
+}
+
+// Limiter holds one token bucket per key.
+type Limiter struct {
+	mu      sync.Mutex
+	clock   Clock
+	buckets map[string]*bucket
+	calls   int
+}
+
+func NewLimiter(clock Clock) *Limiter {
+	return &Limiter{clock: clock, buckets: make(map[string]*bucket)}
+}
+
+// Allow takes a token from every key's bucket, or from none of them. If any
+// bucket is empty it returns false and how long until all of them have a token.
+func (l *Limiter) Allow(keys ...Key) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	l.prune(now)
+
+	var retry time.Duration
+	buckets := make([]*bucket, 0, len(keys))
+	for _, k := range keys {
+		if !k.Limit.Enabled() {
+			continue
+		}
+		b, ok := l.buckets[k.Name]
+		if !ok || b.limit != k.Limit {
+			b = &bucket{tokens: float64(k.Limit.Burst), last: now, limit: k.Limit}
+			l.buckets[k.Name] = b
+		}
+		b.refill(now)
+		retry = max(retry, b.wait())
+		buckets = append(buckets, b)
+	}
+	if retry > 0 {
+		return false, retry
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return true, 0
+}
+
+// prune drops buckets that have refilled completely, every so often, so the
+// map doesn't grow with every user who ever ran a command.
+func (l *Limiter) prune(now time.Time) {
+	l.calls++
+	if l.calls%1000 != 0 {
+		return
+	}
+	for name, b := range l.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(l.buckets, name)
+		}
+	}
+}
+
+// Wait blocks until Allow lets the call through or ctx is done.
+func (l *Limiter) Wait(ctx context.Context, keys ...Key) error {
+	for {
+		ok, retry := l.Allow(keys...)
+		if ok {
+			return nil
+		}
+		timer := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
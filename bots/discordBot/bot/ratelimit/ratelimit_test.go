@@ -0,0 +1,182 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"discordBot/bot/router"
+	"discordBot/bot/router/routertest"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeClock is a Clock that only moves when the test says so.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestAllowBurstThenRefill(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(clock)
+	key := Key{Name: "k", Limit: Limit{Every: Duration(10 * time.Second), Burst: 3}}
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow(key); !ok {
+			t.Fatalf("call %d of the burst was refused", i+1)
+		}
+	}
+	ok, retry := l.Allow(key)
+	if ok {
+		t.Fatal("call after the burst was allowed")
+	}
+	if retry != 10*time.Second {
+		t.Errorf("retry = %v, want 10s", retry)
+	}
+
+	clock.advance(4 * time.Second)
+	if _, retry := l.Allow(key); retry != 6*time.Second {
+		t.Errorf("retry after 4s = %v, want 6s", retry)
+	}
+
+	clock.advance(6 * time.Second)
+	if ok, _ := l.Allow(key); !ok {
+		t.Fatal("call after a full refill period was refused")
+	}
+	if ok, _ := l.Allow(key); ok {
+		t.Fatal("second call after one refill period was allowed")
+	}
+
+	// A long wait refills the bucket up to the burst, not past it.
+	clock.advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow(key); !ok {
+			t.Fatalf("call %d after an hour was refused", i+1)
+		}
+	}
+	if ok, _ := l.Allow(key); ok {
+		t.Fatal("bucket refilled past its burst")
+	}
+}
+
+func TestAllowTakesFromAllOrNone(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(clock)
+	wide := Key{Name: "wide", Limit: Limit{Every: Duration(time.Second), Burst: 5}}
+	narrow := Key{Name: "narrow", Limit: Limit{Every: Duration(time.Minute), Burst: 1}}
+
+	if ok, _ := l.Allow(wide, narrow); !ok {
+		t.Fatal("first call was refused")
+	}
+	// narrow is empty, so wide must not lose a token for the refused calls.
+	for i := 0; i < 10; i++ {
+		if ok, _ := l.Allow(wide, narrow); ok {
+			t.Fatal("call with an empty bucket was allowed")
+		}
+	}
+	for i := 0; i < 4; i++ {
+		if ok, _ := l.Allow(wide); !ok {
+			t.Fatalf("wide lost tokens to refused calls, call %d refused", i+1)
+		}
+	}
+}
+
+func TestAllowIgnoresDisabledLimits(t *testing.T) {
+	l := NewLimiter(newFakeClock())
+	off := Key{Name: "off", Limit: Limit{}}
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.Allow(off); !ok {
+			t.Fatal("a disabled limit refused a call")
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	cfg := Config{Commands: map[string]CommandLimit{
+		"odds":      {Limit: Limit{Every: Duration(time.Minute), Burst: 1}, Scope: ScopeGuild},
+		"odds show": {Limit: Limit{Every: Duration(time.Minute), Burst: 1}, Scope: ScopeGuild},
+	}}
+	tests := []struct {
+		name    string
+		prefix  string
+		first   string
+		second  string
+		refused string
+	}{
+		{name: "root command", prefix: "/", first: "/odds", second: "/odds", refused: "Slow down! Try `/odds` again in 60s."},
+		{name: "limited subcommand", prefix: "/", first: "/odds show", second: "/odds show", refused: "Slow down! Try `/odds show` again in 60s."},
+		{name: "server prefix", prefix: "!", first: "!odds", second: "!odds", refused: "Slow down! Try `!odds` again in 60s."},
+		{name: "unlimited subcommand", prefix: "/", first: "/odds quota", second: "/odds quota"},
+		{name: "separate buckets", prefix: "/", first: "/odds", second: "/odds show"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := router.New()
+			noop := func(req *router.Request) { req.Reply("ok") }
+			r.Register(router.Command{
+				Name:    "odds",
+				Handler: noop,
+				Subcommands: []*router.Command{
+					{Name: "show", Handler: noop},
+					{Name: "quota", Handler: noop},
+				},
+			})
+			r.Use(Check(NewLimiter(newFakeClock()), cfg))
+
+			s := &routertest.Session{}
+			r.Dispatch(tt.prefix, s, routertest.GuildMessage(tt.first))
+			s.Reset()
+			r.Dispatch(tt.prefix, s, routertest.GuildMessage(tt.second))
+			want := tt.refused
+			if want == "" {
+				want = "ok"
+			}
+			if got := s.Contents(); len(got) != 1 || got[0] != want {
+				t.Errorf("second call replied %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDefaultConfigLimitsCostlyCommands(t *testing.T) {
+	cfg := DefaultConfig()
+	for _, name := range []string{"price", "pricehistory", "inventory", "report", "bot-add", "bot-remove", "bot-list", "odds", "servers"} {
+		if _, ok := cfg.Commands[name]; !ok {
+			t.Errorf("no default limit for %s", name)
+		}
+	}
+	if got := cfg.Commands["report"].Scope; got != ScopeGuild {
+		t.Errorf("report scope = %q, want %q", got, ScopeGuild)
+	}
+}
+
+func TestKeysScope(t *testing.T) {
+	limit := Limit{Every: Duration(time.Second), Burst: 1}
+	tests := []struct {
+		scope   Scope
+		guildID string
+		want    string
+	}{
+		{scope: ScopeUser, guildID: "g", want: "cmd:price:user:u"},
+		{scope: ScopeGuild, guildID: "g", want: "cmd:price:guild:g"},
+		{scope: ScopeGuild, guildID: "", want: "cmd:price:user:u"},
+		{scope: ScopeGlobal, guildID: "g", want: "cmd:price:global"},
+	}
+	for _, tt := range tests {
+		cfg := Config{Commands: map[string]CommandLimit{"price": {Limit: limit, Scope: tt.scope}}}
+		req := &router.Request{
+			Command: &router.Command{Name: "price"},
+			GuildID: tt.guildID,
+			Author:  &discordgo.User{ID: "u"},
+		}
+		keys := cfg.Keys(req)
+		if got := keys[len(keys)-1].Name; got != tt.want {
+			t.Errorf("scope %q in guild %q: key %q, want %q", tt.scope, tt.guildID, got, tt.want)
+		}
+	}
+}
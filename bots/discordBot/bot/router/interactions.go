@@ -0,0 +1,275 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"discordBot/util"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxChoices is the most autocomplete suggestions Discord will display.
+const maxChoices = 25
+
+// ApplicationCommands builds the slash command definitions for every
+// registered command and alias.
+func (r *Router) ApplicationCommands() []*discordgo.ApplicationCommand {
+	var out []*discordgo.ApplicationCommand
+	for _, c := range r.Commands() {
+		var options []*discordgo.ApplicationCommandOption
+		if len(c.Subcommands) == 0 {
+			options = c.options()
+		}
+		for _, sub := range c.Subcommands {
+			options = append(options, &discordgo.ApplicationCommandOption{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        sub.Name,
+				Description: sub.shortDescription(),
+				Options:     sub.options(),
+			})
+		}
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			out = append(out, &discordgo.ApplicationCommand{
+				Name:        strings.ToLower(name),
+				Description: c.shortDescription(),
+				Options:     options,
+			})
+		}
+	}
+	return out
+}
+
+func (c *Command) shortDescription() string {
+	desc := c.Description
+	if desc == "" {
+		desc = c.Signature(Prefix)
+	}
+	if len(desc) > 100 {
+		desc = desc[:97] + "..."
+	}
+	return desc
+}
+
+func (c *Command) options() []*discordgo.ApplicationCommandOption {
+	var options []*discordgo.ApplicationCommandOption
+	for _, a := range c.Args {
+		opt := &discordgo.ApplicationCommandOption{
+			Type:         discordgo.ApplicationCommandOptionString,
+			Name:         a.Name,
+			Description:  a.Description,
+			Required:     a.Required,
+			Autocomplete: a.Autocomplete != nil,
+		}
+		if opt.Description == "" {
+			opt.Description = a.Name
+		}
+		if a.Type == ArgInteger {
+			opt.Type = discordgo.ApplicationCommandOptionInteger
+			if a.Min != 0 {
+				min := float64(a.Min)
+				opt.MinValue = &min
+			}
+			opt.MaxValue = float64(a.Max)
+		}
+		if a.Type == ArgChannel {
+			opt.Type = discordgo.ApplicationCommandOptionChannel
+		}
+		for _, choice := range a.Choices {
+			opt.Choices = append(opt.Choices, &discordgo.ApplicationCommandOptionChoice{Name: choice, Value: choice})
+		}
+		options = append(options, opt)
+	}
+	return options
+}
+
+// HandleInteraction answers slash commands, their autocomplete requests and
+// the page buttons on paginated replies. Slash commands are deferred once the
+// checks pass so slow handlers don't time out.
+func (r *Router) HandleInteraction(server Session, i *discordgo.InteractionCreate) {
+	ctx, err := r.begin()
+	if err != nil {
+		return
+	}
+	defer r.inflight.Done()
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		r.runInteraction(ctx, server, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		r.autocomplete(server, i)
+	case discordgo.InteractionMessageComponent:
+		r.handlePageButton(server, i)
+	}
+}
+
+func (r *Router) runInteraction(ctx context.Context, server Session, i *discordgo.InteractionCreate) {
+	logger := util.LoggerInit("ROUTER", "HandleInteraction")
+	data := i.ApplicationCommandData()
+	cmd, options, ok := r.lookupInteraction(data)
+	if !ok {
+		return
+	}
+
+	req := NewInteractionRequest(server, i)
+	req.ctx = ctx
+	req.router = r
+	req.Command = cmd
+	req.Name = data.Name
+	req.Args = cmd.interactionArgs(options)
+
+	// Checks run before deferring so a refusal can be shown only to the caller.
+	if err := r.check(req); err != nil {
+		reason := err.Error()
+		if errors.Is(err, ErrIgnore) {
+			reason = "This command isn't available here."
+		}
+		server.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{Content: reason, Flags: discordgo.MessageFlagsEphemeral},
+		})
+		return
+	}
+
+	err := server.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	})
+	if err != nil {
+		logger.Error("Failed to defer interaction", "command", data.Name, "error", err)
+		return
+	}
+	cmd.Handler(req)
+
+	// A handler that never replied would leave the "thinking..." message behind.
+	if !req.hasResponded() {
+		server.InteractionResponseDelete(i.Interaction)
+	}
+}
+
+// lookupInteraction resolves the command, descending into a subcommand if one
+// was used, and returns the options that belong to it.
+func (r *Router) lookupInteraction(data discordgo.ApplicationCommandInteractionData) (*Command, []*discordgo.ApplicationCommandInteractionDataOption, bool) {
+	cmd, ok := r.Lookup(data.Name)
+	if !ok {
+		return nil, nil, false
+	}
+	options := data.Options
+	if len(options) == 1 && options[0].Type == discordgo.ApplicationCommandOptionSubCommand {
+		sub, found := cmd.Subcommand(options[0].Name)
+		if !found {
+			return nil, nil, false
+		}
+		return sub, options[0].Options, true
+	}
+	return cmd, options, cmd.Handler != nil
+}
+
+// NewInteractionRequest builds a Request for an interaction without a command attached.
+func NewInteractionRequest(server Session, i *discordgo.InteractionCreate) *Request {
+	return &Request{
+		Session:     server,
+		Interaction: i,
+		ChannelID:   i.ChannelID,
+		GuildID:     i.GuildID,
+		Author:      interactionUser(i),
+		Member:      i.Member,
+	}
+}
+
+func interactionUser(i *discordgo.InteractionCreate) *discordgo.User {
+	if i.Member != nil {
+		return i.Member.User
+	}
+	return i.User
+}
+
+// interactionArgs lines the submitted options up in the order of the arg spec,
+// so handlers read them the same way as text command words. Options left out
+// are "", like words a text command didn't give, so later options keep their
+// position.
+func (c *Command) interactionArgs(options []*discordgo.ApplicationCommandInteractionDataOption) []string {
+	values := make(map[string]string, len(options))
+	for _, opt := range options {
+		values[opt.Name] = optionString(opt)
+	}
+	args := make([]string, len(c.Args))
+	for i, a := range c.Args {
+		args[i] = values[a.Name]
+	}
+	for len(args) > 0 && args[len(args)-1] == "" {
+		args = args[:len(args)-1]
+	}
+	return args
+}
+
+func optionString(opt *discordgo.ApplicationCommandInteractionDataOption) string {
+	switch opt.Type {
+	case discordgo.ApplicationCommandOptionInteger:
+		return strconv.FormatInt(opt.IntValue(), 10)
+	case discordgo.ApplicationCommandOptionNumber:
+		return strconv.FormatFloat(opt.FloatValue(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(opt.Value)
+	}
+}
+
+func (r *Router) autocomplete(server Session, i *discordgo.InteractionCreate) {
+	data := i.ApplicationCommandData()
+	cmd, options, ok := r.lookupInteraction(data)
+	if !ok {
+		return
+	}
+	var choices []*discordgo.ApplicationCommandOptionChoice
+	for _, opt := range options {
+		if !opt.Focused {
+			continue
+		}
+		for _, a := range cmd.Args {
+			if a.Name != opt.Name || a.Autocomplete == nil {
+				continue
+			}
+			for _, s := range a.Autocomplete(optionString(opt)) {
+				if len(choices) == maxChoices {
+					break
+				}
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: s, Value: s})
+			}
+		}
+	}
+	server.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// respond fills in the deferred interaction response on the first call and
+// sends a follow-up message after that.
+func (r *Request) respond(params *discordgo.WebhookParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.responded {
+		r.responded = true
+		edit := &discordgo.WebhookEdit{Files: params.Files}
+		if params.Content != "" {
+			edit.Content = &params.Content
+		}
+		if len(params.Embeds) > 0 {
+			edit.Embeds = &params.Embeds
+		}
+		if len(params.Components) > 0 {
+			edit.Components = &params.Components
+		}
+		_, err := r.Session.InteractionResponseEdit(r.Interaction.Interaction, edit)
+		return err
+	}
+	_, err := r.Session.FollowupMessageCreate(r.Interaction.Interaction, true, params)
+	return err
+}
+
+func (r *Request) hasResponded() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.responded
+}
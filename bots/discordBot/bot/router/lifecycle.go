@@ -0,0 +1,52 @@
+package router
+
+import (
+	"context"
+	"errors"
+)
+
+// errShuttingDown is returned by begin once Shutdown has been called.
+var errShuttingDown = errors.New("router: shutting down")
+
+// Context returns the request's context. It is cancelled when the bot shuts
+// down before the handler finishes, so long-running work should pass it on.
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// begin registers an in-flight handler and returns the context it runs under.
+// Every successful begin must be paired with r.inflight.Done.
+func (r *Router) begin() (context.Context, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closing {
+		return nil, errShuttingDown
+	}
+	r.inflight.Add(1)
+	return r.ctx, nil
+}
+
+// Shutdown stops new commands from starting and waits for running ones to
+// finish. If ctx expires first, the running handlers' contexts are cancelled
+// and ctx's error is returned.
+func (r *Router) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	r.closing = true
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.inflight.Wait()
+		close(done)
+	}()
+	defer r.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,204 @@
+package router
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// pageTTL is how long Previous/Next keep working on a paginated reply.
+	pageTTL = 15 * time.Minute
+	// embedDescriptionLimit is Discord's cap on an embed description.
+	embedDescriptionLimit = 4096
+	pageButtonPrefix      = "page:"
+)
+
+// pageSet is one paginated reply waiting for button presses.
+type pageSet struct {
+	pages   []*discordgo.MessageEmbed
+	ownerID string
+	expires time.Time
+}
+
+// pager remembers paginated replies so their buttons can flip pages.
+type pager struct {
+	mu   sync.Mutex
+	sets map[string]*pageSet
+}
+
+func (p *pager) add(set *pageSet) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sets == nil {
+		p.sets = make(map[string]*pageSet)
+	}
+	now := time.Now()
+	for id, s := range p.sets {
+		if now.After(s.expires) {
+			delete(p.sets, id)
+		}
+	}
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	id := hex.EncodeToString(buf)
+	p.sets[id] = set
+	return id
+}
+
+func (p *pager) get(id string) (*pageSet, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	set, ok := p.sets[id]
+	if !ok || time.Now().After(set.expires) {
+		return nil, false
+	}
+	return set, true
+}
+
+// Paginate spreads lines over embeds of at most perPage lines each, all with
+// the same title. Each page is also kept under Discord's description limit.
+func Paginate(title string, lines []string, perPage int) []*discordgo.MessageEmbed {
+	var pages []*discordgo.MessageEmbed
+	var current []string
+	size := 0
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		pages = append(pages, &discordgo.MessageEmbed{
+			Title:       title,
+			Color:       0x00ffcc,
+			Description: strings.Join(current, "\n"),
+		})
+		current, size = nil, 0
+	}
+	for _, line := range lines {
+		if len(line) > embedDescriptionLimit {
+			line = line[:embedDescriptionLimit-3] + "..."
+		}
+		if len(current) == perPage || size+len(line)+1 > embedDescriptionLimit {
+			flush()
+		}
+		current = append(current, line)
+		size += len(line) + 1
+	}
+	flush()
+	return pages
+}
+
+// ReplyPages sends pages as one embed with Previous/Next buttons. A single
+// page is sent as a plain embed.
+func (r *Request) ReplyPages(pages []*discordgo.MessageEmbed) error {
+	if len(pages) == 0 {
+		return nil
+	}
+	if len(pages) == 1 || r.router == nil {
+		return r.ReplyEmbed(pages[0])
+	}
+	id := r.router.pages.add(&pageSet{
+		pages:   pages,
+		ownerID: r.Author.ID,
+		expires: time.Now().Add(pageTTL),
+	})
+	embed, components := pageView(id, pages, 0)
+	if r.Interaction != nil {
+		return r.respond(&discordgo.WebhookParams{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		})
+	}
+	_, err := r.Session.ChannelMessageSendComplex(r.ChannelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	return err
+}
+
+// pageView renders page n of a set with its buttons, adding the page number
+// to the footer.
+func pageView(id string, pages []*discordgo.MessageEmbed, n int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := *pages[n]
+	footer := fmt.Sprintf("Page %d/%d", n+1, len(pages))
+	if embed.Footer != nil && embed.Footer.Text != "" {
+		footer = embed.Footer.Text + " · " + footer
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "Previous",
+				Style:    discordgo.SecondaryButton,
+				CustomID: pageButtonPrefix + id + ":" + strconv.Itoa(n-1),
+				Disabled: n == 0,
+			},
+			discordgo.Button{
+				Label:    "Next",
+				Style:    discordgo.SecondaryButton,
+				CustomID: pageButtonPrefix + id + ":" + strconv.Itoa(n+1),
+				Disabled: n == len(pages)-1,
+			},
+		}},
+	}
+	return &embed, components
+}
+
+// handlePageButton flips a paginated reply when Previous or Next is pressed.
+func (r *Router) handlePageButton(server Session, i *discordgo.InteractionCreate) {
+	customID := i.MessageComponentData().CustomID
+	id, n, ok := parsePageButton(customID)
+	if !ok {
+		return
+	}
+	set, ok := r.pages.get(id)
+	if !ok {
+		server.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{Components: []discordgo.MessageComponent{}},
+		})
+		return
+	}
+	if user := interactionUser(i); user == nil || user.ID != set.ownerID {
+		server.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: "Only the person who ran the command can turn the pages.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+		return
+	}
+	if n < 0 || n >= len(set.pages) {
+		return
+	}
+	embed, components := pageView(id, set.pages, n)
+	server.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+}
+
+func parsePageButton(customID string) (id string, n int, ok bool) {
+	rest, found := strings.CutPrefix(customID, pageButtonPrefix)
+	if !found {
+		return "", 0, false
+	}
+	id, num, found := strings.Cut(rest, ":")
+	if !found {
+		return "", 0, false
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return "", 0, false
+	}
+	return id, n, true
+}
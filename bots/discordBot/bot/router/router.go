@@ -0,0 +1,410 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"discordBot/util"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Prefix is the default character text commands start with.
+const Prefix = "/"
+
+// Handler runs a command once its arguments have been validated.
+type Handler func(req *Request)
+
+// Check runs before every handler. Returning an error stops the command and
+// the error text is sent back to the user.
+type Check func(req *Request) error
+
+// ErrIgnore stops a command without replying.
+var ErrIgnore = errors.New("router: command ignored")
+
+// Deny returns a check error whose text is shown to the user as-is.
+func Deny(reason string) error {
+	return denial(reason)
+}
+
+type denial string
+
+func (d denial) Error() string { return string(d) }
+
+// ArgType is the Discord option type an argument is registered as.
+type ArgType int
+
+const (
+	ArgString ArgType = iota
+	ArgInteger
+	ArgChannel
+)
+
+// Permission names something a command needs the caller to be allowed to do.
+type Permission string
+
+// Completer suggests values for a partially typed argument.
+type Completer func(partial string) []string
+
+// Arg describes one positional argument of a command.
+type Arg struct {
+	Name        string
+	Description string
+	Type        ArgType
+	Required    bool
+	// Rest joins every remaining word of a text command into this argument,
+	// leaving any args after it to slash command options.
+	Rest bool
+	// Choices limits the argument to a fixed set of values in the slash command UI.
+	Choices []string
+	// Min and Max bound an ArgInteger. Zero means unbounded.
+	Min, Max int
+	// Autocomplete offers suggestions while the slash command is being typed.
+	Autocomplete Completer
+}
+
+// Command is a single registered bot command.
+type Command struct {
+	Name    string
+	Aliases []string
+	// Module groups commands so a server can switch them off together.
+	Module string
+	// Permission is what the caller needs to run the command. Empty means anyone.
+	Permission Permission
+	Args       []Arg
+	// Usage replaces the generated "Usage: ..." reply when the arguments don't match.
+	Usage       string
+	Description string
+	Handler     Handler
+	// Subcommands are matched on the first word after the command name.
+	// Slash commands have to pick one. A text command that names none runs
+	// the command's own Handler and Args, if it has them, e.g. "/odds soccer_epl".
+	Subcommands []*Command
+
+	parent *Command
+}
+
+// Request is what a handler receives: the session, where the command came
+// from and the parsed arguments. Exactly one of Message and Interaction is set.
+type Request struct {
+	Session     Session
+	Message     *discordgo.MessageCreate
+	Interaction *discordgo.InteractionCreate
+	Command     *Command
+	// Name is the command name or alias the user typed, without the prefix.
+	Name      string
+	Args      []string
+	ChannelID string
+	GuildID   string
+	Author    *discordgo.User
+	// Member is the caller's server membership, nil in DMs.
+	Member *discordgo.Member
+
+	ctx       context.Context
+	router    *Router
+	prefix    string
+	mu        sync.Mutex
+	responded bool
+}
+
+// Router dispatches messages to registered commands on an exact name match.
+type Router struct {
+	mu       sync.RWMutex
+	commands map[string]*Command
+	order    []*Command
+	checks   []Check
+	pages    pager
+
+	// ctx is cancelled when Shutdown gives up waiting for in-flight handlers.
+	ctx      context.Context
+	cancel   context.CancelFunc
+	inflight sync.WaitGroup
+	closing  bool
+}
+
+func New() *Router {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Router{commands: make(map[string]*Command), ctx: ctx, cancel: cancel}
+}
+
+// Register adds cmd under its name and aliases. It panics on a duplicate name,
+// since that is always a programming error.
+func (r *Router) Register(cmd Command) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := &cmd
+	for _, name := range append([]string{c.Name}, c.Aliases...) {
+		key := strings.ToLower(name)
+		if _, exists := r.commands[key]; exists {
+			panic("router: duplicate command " + name)
+		}
+		r.commands[key] = c
+	}
+	for _, sub := range c.Subcommands {
+		sub.parent = c
+		if sub.Module == "" {
+			sub.Module = c.Module
+		}
+		if sub.Permission == "" {
+			sub.Permission = c.Permission
+		}
+	}
+	r.order = append(r.order, c)
+}
+
+// Use adds a check that runs before every command.
+func (r *Router) Use(check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check)
+}
+
+// Lookup finds a command by name or alias.
+func (r *Router) Lookup(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.commands[strings.ToLower(name)]
+	return c, ok
+}
+
+// Commands returns every registered command in registration order.
+func (r *Router) Commands() []*Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]*Command(nil), r.order...)
+}
+
+// Subcommand finds a subcommand of c by name.
+func (c *Command) Subcommand(name string) (*Command, bool) {
+	for _, sub := range c.Subcommands {
+		if strings.EqualFold(sub.Name, name) {
+			return sub, true
+		}
+	}
+	return nil, false
+}
+
+// Match splits content into a command and its raw words. ok is false if content
+// does not start with prefix or the command is unknown. For commands with
+// subcommands, cmd is the subcommand named by the next word if there is one.
+func (r *Router) Match(prefix, content string) (cmd *Command, name string, words []string, ok bool) {
+	if !strings.HasPrefix(content, prefix) {
+		return nil, "", nil, false
+	}
+	parts := util.SplitArgs(strings.TrimPrefix(content, prefix))
+	if len(parts) == 0 {
+		return nil, "", nil, false
+	}
+	cmd, ok = r.Lookup(parts[0])
+	if !ok {
+		return nil, parts[0], nil, false
+	}
+	words = parts[1:]
+	if len(cmd.Subcommands) > 0 && len(words) > 0 {
+		if sub, found := cmd.Subcommand(words[0]); found {
+			return sub, parts[0], words[1:], true
+		}
+	}
+	return cmd, parts[0], words, true
+}
+
+// Dispatch runs the command in message, if it starts with prefix. It returns
+// false if the message isn't a known command.
+func (r *Router) Dispatch(prefix string, server Session, message *discordgo.MessageCreate) bool {
+	cmd, name, words, ok := r.Match(prefix, message.Content)
+	if !ok {
+		return false
+	}
+	ctx, err := r.begin()
+	if err != nil {
+		return true
+	}
+	defer r.inflight.Done()
+	req := &Request{
+		ctx:       ctx,
+		Session:   server,
+		Message:   message,
+		Command:   cmd,
+		Name:      name,
+		ChannelID: message.ChannelID,
+		GuildID:   message.GuildID,
+		Author:    message.Author,
+		Member:    message.Member,
+		router:    r,
+		prefix:    prefix,
+	}
+	if err := r.check(req); err != nil {
+		if !errors.Is(err, ErrIgnore) {
+			req.Reply(err.Error())
+		}
+		return true
+	}
+	if cmd.Handler == nil {
+		req.Reply(cmd.UsageText(req.Prefix()))
+		return true
+	}
+	args, ok := cmd.ParseArgs(words)
+	if !ok {
+		req.Reply(cmd.UsageText(req.Prefix()))
+		return true
+	}
+	req.Args = args
+	cmd.Handler(req)
+	return true
+}
+
+func (r *Router) check(req *Request) error {
+	r.mu.RLock()
+	checks := r.checks
+	r.mu.RUnlock()
+	for _, check := range checks {
+		if err := check(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseArgs maps words onto the command's argument spec.
+func (c *Command) ParseArgs(words []string) ([]string, bool) {
+	required := 0
+	rest := false
+	for _, a := range c.Args {
+		if a.Required {
+			required++
+		}
+		rest = rest || a.Rest
+	}
+	if len(words) < required {
+		return nil, false
+	}
+	if !rest && len(words) > len(c.Args) {
+		return nil, false
+	}
+	args := make([]string, 0, len(c.Args))
+	for i, a := range c.Args {
+		if i >= len(words) {
+			break
+		}
+		if a.Rest {
+			args = append(args, strings.Join(words[i:], " "))
+			break
+		}
+		word := words[i]
+		if a.Type == ArgChannel {
+			word = strings.TrimSuffix(strings.TrimPrefix(word, "<#"), ">")
+		}
+		args = append(args, word)
+	}
+	return args, true
+}
+
+// UsageText is the reply sent when a command is called with the wrong
+// arguments, with its commands started by prefix.
+func (c *Command) UsageText(prefix string) string {
+	if c.Usage != "" {
+		root := c.Name
+		if c.parent != nil {
+			root = c.parent.Name
+		}
+		return strings.ReplaceAll(c.Usage, Prefix+root, prefix+root)
+	}
+	if len(c.Subcommands) > 0 {
+		lines := make([]string, 0, len(c.Subcommands))
+		for _, sub := range c.Subcommands {
+			lines = append(lines, sub.Signature(prefix))
+		}
+		return "Usage:\n" + strings.Join(lines, "\n")
+	}
+	return "Usage: " + c.Signature(prefix)
+}
+
+// FullName is the command name including its parent, e.g. "config channel".
+func (c *Command) FullName() string {
+	if c.parent != nil {
+		return c.parent.Name + " " + c.Name
+	}
+	return c.Name
+}
+
+// Signature renders the command and its arguments after prefix, e.g.
+// "/inbox <sid_token>".
+func (c *Command) Signature(prefix string) string {
+	var b strings.Builder
+	b.WriteString(prefix + c.FullName())
+	for _, a := range c.Args {
+		name := a.Name
+		if a.Rest {
+			name += "..."
+		}
+		if len(a.Choices) > 0 {
+			name = strings.Join(a.Choices, "|")
+		}
+		if a.Required {
+			b.WriteString(" <" + name + ">")
+		} else {
+			b.WriteString(" [" + name + "]")
+		}
+	}
+	return b.String()
+}
+
+// Prefix is what the command was started with: the server's prefix for a
+// text command, "/" for a slash command.
+func (r *Request) Prefix() string {
+	if r.prefix == "" {
+		return Prefix
+	}
+	return r.prefix
+}
+
+// Arg returns the i'th parsed argument, or "" if it wasn't given.
+func (r *Request) Arg(i int) string {
+	if i < len(r.Args) {
+		return r.Args[i]
+	}
+	return ""
+}
+
+// Reply sends content to the channel the command came from, split over
+// several messages if it's too long for one. For slash commands the first
+// reply fills in the deferred response.
+func (r *Request) Reply(content string) error {
+	for _, chunk := range SplitMessage(content, MessageLimit) {
+		var err error
+		if r.Interaction != nil {
+			err = r.respond(&discordgo.WebhookParams{Content: chunk})
+		} else {
+			_, err = r.Session.ChannelMessageSend(r.ChannelID, chunk)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplyEmbed sends embed to the channel the command came from.
+func (r *Request) ReplyEmbed(embed *discordgo.MessageEmbed) error {
+	if r.Interaction != nil {
+		return r.respond(&discordgo.WebhookParams{Embeds: []*discordgo.MessageEmbed{embed}})
+	}
+	_, err := r.Session.ChannelMessageSendEmbed(r.ChannelID, embed)
+	return err
+}
+
+// ReplyFiles sends embed with files attached. The embed can show an attached
+// image by pointing at "attachment://<file name>".
+func (r *Request) ReplyFiles(embed *discordgo.MessageEmbed, files ...*discordgo.File) error {
+	if r.Interaction != nil {
+		return r.respond(&discordgo.WebhookParams{Embeds: []*discordgo.MessageEmbed{embed}, Files: files})
+	}
+	_, err := r.Session.ChannelMessageSendComplex(r.ChannelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  files,
+	})
+	return err
+}
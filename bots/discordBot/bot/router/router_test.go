@@ -0,0 +1,89 @@
+package router
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseArgs(t *testing.T) {
+	cmd := &Command{Name: "price", Args: []Arg{
+		{Name: "game", Required: true},
+		{Name: "currency"},
+		{Name: "channel", Type: ArgChannel},
+	}}
+	rest := &Command{Name: "movement", Args: []Arg{
+		{Name: "days"},
+		{Name: "match", Rest: true},
+	}}
+	tests := []struct {
+		name  string
+		cmd   *Command
+		words []string
+		want  []string
+		ok    bool
+	}{
+		{name: "missing required", cmd: cmd, words: nil, ok: false},
+		{name: "required only", cmd: cmd, words: []string{"cs2"}, want: []string{"cs2"}, ok: true},
+		{name: "all", cmd: cmd, words: []string{"cs2", "EUR", "<#42>"}, want: []string{"cs2", "EUR", "42"}, ok: true},
+		{name: "too many", cmd: cmd, words: []string{"cs2", "EUR", "42", "x"}, ok: false},
+		{name: "rest joins the remaining words", cmd: rest, words: []string{"7", "arsenal", "spurs"}, want: []string{"7", "arsenal spurs"}, ok: true},
+		{name: "rest left out", cmd: rest, words: []string{"7"}, want: []string{"7"}, ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.cmd.ParseArgs(tt.words)
+			if ok != tt.ok || !slices.Equal(got, tt.want) {
+				t.Errorf("ParseArgs(%q) = %q, %v, want %q, %v", tt.words, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestInteractionArgs(t *testing.T) {
+	cmd := &Command{Name: "price", Args: []Arg{
+		{Name: "item", Required: true},
+		{Name: "game"},
+		{Name: "currency"},
+	}}
+	option := func(name, value string) *discordgo.ApplicationCommandInteractionDataOption {
+		return &discordgo.ApplicationCommandInteractionDataOption{Name: name, Type: discordgo.ApplicationCommandOptionString, Value: value}
+	}
+	tests := []struct {
+		name    string
+		options []*discordgo.ApplicationCommandInteractionDataOption
+		want    []string
+	}{
+		{name: "none", options: nil, want: []string{}},
+		{name: "in order", options: []*discordgo.ApplicationCommandInteractionDataOption{option("item", "x"), option("game", "cs2")}, want: []string{"x", "cs2"}},
+		{name: "out of order", options: []*discordgo.ApplicationCommandInteractionDataOption{option("currency", "EUR"), option("item", "x")}, want: []string{"x", "", "EUR"}},
+		{name: "only a later option", options: []*discordgo.ApplicationCommandInteractionDataOption{option("currency", "EUR")}, want: []string{"", "", "EUR"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cmd.interactionArgs(tt.options); !slices.Equal(got, tt.want) {
+				t.Errorf("interactionArgs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignature(t *testing.T) {
+	parent := &Command{Name: "watch"}
+	sub := &Command{Name: "add", parent: parent, Args: []Arg{
+		{Name: "direction", Required: true, Choices: []string{"below", "above"}},
+		{Name: "price", Required: true},
+		{Name: "item_name", Required: true, Rest: true},
+	}}
+	if got, want := sub.Signature(Prefix), "/watch add <below|above> <price> <item_name...>"; got != want {
+		t.Errorf("Signature() = %q, want %q", got, want)
+	}
+	if got, want := sub.UsageText("!"), "Usage: !watch add <below|above> <price> <item_name...>"; got != want {
+		t.Errorf("UsageText() = %q, want %q", got, want)
+	}
+	sub.Usage = "Usage: /watch add below|above <price> <item_name...>, e.g. /watch add below 12.50 ak redline ft"
+	if got, want := sub.UsageText("!"), "Usage: !watch add below|above <price> <item_name...>, e.g. !watch add below 12.50 ak redline ft"; got != want {
+		t.Errorf("UsageText() = %q, want %q", got, want)
+	}
+}
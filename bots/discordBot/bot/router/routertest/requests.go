@@ -0,0 +1,41 @@
+package routertest
+
+import (
+	"discordBot/bot/router"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Default IDs used by the builders below.
+const (
+	UserID    = "100"
+	ChannelID = "200"
+	GuildID   = "300"
+)
+
+// DM builds a direct message from UserID.
+func DM(content string) *discordgo.MessageCreate {
+	return &discordgo.MessageCreate{Message: &discordgo.Message{
+		ID:        "1",
+		ChannelID: DMChannel(UserID),
+		Content:   content,
+		Author:    &discordgo.User{ID: UserID, Username: "tester"},
+	}}
+}
+
+// GuildMessage builds a message from UserID in ChannelID of GuildID.
+func GuildMessage(content string) *discordgo.MessageCreate {
+	m := DM(content)
+	m.ChannelID = ChannelID
+	m.GuildID = GuildID
+	m.Member = &discordgo.Member{User: m.Author}
+	return m
+}
+
+// Run dispatches a text command through r with the default prefix and
+// returns the messages it sent. ok is false when no command matched.
+func Run(r *router.Router, message *discordgo.MessageCreate) (sent []Message, ok bool) {
+	s := &Session{}
+	ok = r.Dispatch(router.Prefix, s, message)
+	return s.Sent(), ok
+}
@@ -0,0 +1,301 @@
+// Package routertest provides an in-memory router.Session that records
+// everything the bot sends, so commands can be run without Discord.
+package routertest
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"discordBot/bot/router"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Message is one message the bot sent, as the fake saw it.
+type Message struct {
+	ID         string
+	ChannelID  string
+	Content    string
+	Embeds     []*discordgo.MessageEmbed
+	Components []discordgo.MessageComponent
+	Files      []*discordgo.File
+	// Ephemeral is set for interaction replies only the caller can see.
+	Ephemeral bool
+}
+
+// Response is one interaction callback: a respond, edit or delete.
+type Response struct {
+	Interaction *discordgo.Interaction
+	Type        discordgo.InteractionResponseType
+	// Edit is set for InteractionResponseEdit calls.
+	Edit *discordgo.WebhookEdit
+	// Deleted is set for InteractionResponseDelete calls.
+	Deleted bool
+	Data    *discordgo.InteractionResponseData
+}
+
+// Session is a fake router.Session. The zero value is ready to use.
+type Session struct {
+	mu sync.Mutex
+
+	// Permissions is what UserChannelPermissions returns for every user.
+	Permissions int64
+	// History is what ChannelMessages returns, newest first, by channel ID.
+	History map[string][]*discordgo.Message
+	// Channels is what Channel returns by ID. Any other channel is a DM
+	// channel if UserChannelCreate would have opened it, else a text channel
+	// in GuildID.
+	Channels map[string]*discordgo.Channel
+	// Err, if set, is returned from every call that can fail.
+	Err error
+
+	sent      []Message
+	responses []Response
+	deleted   []string
+	typing    []string
+	nextID    int
+}
+
+var _ router.Session = (*Session)(nil)
+
+// Sent returns every message sent so far, including interaction replies.
+func (s *Session) Sent() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.sent...)
+}
+
+// SentTo returns the messages sent to one channel.
+func (s *Session) SentTo(channelID string) []Message {
+	var out []Message
+	for _, m := range s.Sent() {
+		if m.ChannelID == channelID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Contents returns just the text of every message sent so far.
+func (s *Session) Contents() []string {
+	var out []string
+	for _, m := range s.Sent() {
+		out = append(out, m.Content)
+	}
+	return out
+}
+
+// Responses returns every interaction callback so far.
+func (s *Session) Responses() []Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Response(nil), s.responses...)
+}
+
+// Deleted returns the IDs of deleted messages.
+func (s *Session) Deleted() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.deleted...)
+}
+
+// Typing returns the channels the bot showed a typing indicator in.
+func (s *Session) Typing() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.typing...)
+}
+
+// Reset forgets everything recorded so far.
+func (s *Session) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent, s.responses, s.deleted, s.typing = nil, nil, nil, nil
+}
+
+// record stores m and returns it as the *discordgo.Message Discord would send back.
+func (s *Session) record(m Message) (*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	s.nextID++
+	m.ID = strconv.Itoa(s.nextID)
+	s.sent = append(s.sent, m)
+	return &discordgo.Message{
+		ID:         m.ID,
+		ChannelID:  m.ChannelID,
+		Content:    m.Content,
+		Embeds:     m.Embeds,
+		Components: m.Components,
+	}, nil
+}
+
+func (s *Session) ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.record(Message{ChannelID: channelID, Content: content})
+}
+
+func (s *Session) ChannelMessageSendEmbed(channelID string, embed *discordgo.MessageEmbed, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.record(Message{ChannelID: channelID, Embeds: []*discordgo.MessageEmbed{embed}})
+}
+
+func (s *Session) ChannelMessageSendComplex(channelID string, data *discordgo.MessageSend, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	embeds := data.Embeds
+	if data.Embed != nil {
+		embeds = append([]*discordgo.MessageEmbed{data.Embed}, embeds...)
+	}
+	return s.record(Message{
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     embeds,
+		Components: data.Components,
+		Files:      data.Files,
+	})
+}
+
+func (s *Session) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	history := s.History[channelID]
+	if beforeID != "" {
+		for i, m := range history {
+			if m.ID == beforeID {
+				history = history[i+1:]
+				break
+			}
+		}
+	}
+	if limit > 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return append([]*discordgo.Message(nil), history...), nil
+}
+
+func (s *Session) ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.deleted = append(s.deleted, messageID)
+	return nil
+}
+
+func (s *Session) ChannelTyping(channelID string, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.typing = append(s.typing, channelID)
+	return nil
+}
+
+// UserChannelCreate opens a DM channel whose ID is "dm:" plus the user's ID.
+func (s *Session) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return &discordgo.Channel{ID: DMChannel(recipientID), Type: discordgo.ChannelTypeDM}, nil
+}
+
+func (s *Session) UserChannelPermissions(userID, channelID string, fetchOptions ...discordgo.RequestOption) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return 0, s.Err
+	}
+	return s.Permissions, nil
+}
+
+func (s *Session) Channel(channelID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if c, ok := s.Channels[channelID]; ok {
+		return c, nil
+	}
+	if strings.HasPrefix(channelID, DMChannel("")) {
+		return &discordgo.Channel{ID: channelID, Type: discordgo.ChannelTypeDM}, nil
+	}
+	return &discordgo.Channel{ID: channelID, GuildID: GuildID, Type: discordgo.ChannelTypeGuildText}, nil
+}
+
+// InteractionRespond records resp. Replies that carry a message are also
+// added to Sent, in the interaction's channel.
+func (s *Session) InteractionRespond(interaction *discordgo.Interaction, resp *discordgo.InteractionResponse, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	if s.Err != nil {
+		s.mu.Unlock()
+		return s.Err
+	}
+	s.responses = append(s.responses, Response{Interaction: interaction, Type: resp.Type, Data: resp.Data})
+	s.mu.Unlock()
+	if resp.Type != discordgo.InteractionResponseChannelMessageWithSource || resp.Data == nil {
+		return nil
+	}
+	_, err := s.record(Message{
+		ChannelID:  interaction.ChannelID,
+		Content:    resp.Data.Content,
+		Embeds:     resp.Data.Embeds,
+		Components: resp.Data.Components,
+		Files:      resp.Data.Files,
+		Ephemeral:  resp.Data.Flags&discordgo.MessageFlagsEphemeral != 0,
+	})
+	return err
+}
+
+// InteractionResponseEdit records the edit and adds it to Sent, the way the
+// reply to a deferred interaction shows up in the channel.
+func (s *Session) InteractionResponseEdit(interaction *discordgo.Interaction, newresp *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	s.mu.Lock()
+	if s.Err != nil {
+		s.mu.Unlock()
+		return nil, s.Err
+	}
+	s.responses = append(s.responses, Response{Interaction: interaction, Edit: newresp})
+	s.mu.Unlock()
+	m := Message{ChannelID: interaction.ChannelID, Files: newresp.Files}
+	if newresp.Content != nil {
+		m.Content = *newresp.Content
+	}
+	if newresp.Embeds != nil {
+		m.Embeds = *newresp.Embeds
+	}
+	if newresp.Components != nil {
+		m.Components = *newresp.Components
+	}
+	return s.record(m)
+}
+
+func (s *Session) InteractionResponseDelete(interaction *discordgo.Interaction, options ...discordgo.RequestOption) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.responses = append(s.responses, Response{Interaction: interaction, Deleted: true})
+	return nil
+}
+
+func (s *Session) FollowupMessageCreate(interaction *discordgo.Interaction, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	return s.record(Message{
+		ChannelID:  interaction.ChannelID,
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: data.Components,
+		Files:      data.Files,
+		Ephemeral:  data.Flags&discordgo.MessageFlagsEphemeral != 0,
+	})
+}
+
+// DMChannel is the ID the fake gives the DM channel with userID.
+func DMChannel(userID string) string {
+	return "dm:" + userID
+}
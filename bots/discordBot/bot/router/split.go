@@ -0,0 +1,105 @@
+package router
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MessageLimit is the most characters Discord accepts in one message.
+const MessageLimit = 2000
+
+const fence = "```"
+
+// SplitMessage breaks text into chunks of at most limit bytes, splitting on
+// line boundaries where it can. A code block cut in two is closed at the end
+// of one chunk and reopened, with the same language tag, at the start of the next.
+func SplitMessage(text string, limit int) []string {
+	if len(text) <= limit {
+		return []string{text}
+	}
+
+	var (
+		chunks  []string
+		current strings.Builder
+		// openFence is the fence that opened the code block we're inside, or "".
+		openFence string
+		// header is how much of current is the reopened fence, not new text.
+		header int
+	)
+	flush := func() {
+		chunk := current.String()
+		if openFence != "" {
+			chunk += fence
+		}
+		chunks = append(chunks, chunk)
+		current.Reset()
+		header = 0
+		if openFence != "" {
+			current.WriteString(openFence + "\n")
+			header = current.Len()
+		}
+	}
+	// room is what's left once the closing fence a split may need is reserved.
+	room := func() int {
+		return limit - current.Len() - len(fence)
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for line != "" {
+			if len(line) <= room() {
+				current.WriteString(line)
+				openFence = trackFence(openFence, line)
+				break
+			}
+			if current.Len() > header {
+				flush()
+				continue
+			}
+			// A single line longer than a whole message: cut it where it overflows.
+			cut := room()
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			current.WriteString(line[:cut])
+			openFence = trackFence(openFence, line[:cut])
+			line = line[cut:]
+			flush()
+		}
+	}
+	if current.Len() > header {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// trackFence returns the fence that is open after line, given the one open before it.
+func trackFence(open, line string) string {
+	for {
+		idx := strings.Index(line, fence)
+		if idx < 0 {
+			return open
+		}
+		line = line[idx+len(fence):]
+		if open != "" {
+			open = ""
+			continue
+		}
+		open = fence + languageTag(line)
+	}
+}
+
+// languageTag returns the code block language right after an opening fence,
+// e.g. "go" for "```go". Text that doesn't look like a language gives "".
+func languageTag(rest string) string {
+	end := strings.IndexAny(rest, "`\n")
+	if end < 0 {
+		end = len(rest)
+	}
+	tag := rest[:end]
+	for _, r := range tag {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("+-#_.", r)) {
+			return ""
+		}
+	}
+	return tag
+}
@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"context"
+	"sync"
+
+	"discordBot/bot/config"
+	"discordBot/bot/router"
+	betting "discordBot/functions/betting"
+	"discordBot/functions/servercheck"
+	steammarket "discordBot/functions/steamMarket"
+)
+
+// services is the long-lived state that commands and background jobs share.
+type services struct {
+	bot     *config.Bot
+	guilds  *config.Guilds
+	market  *steammarket.Market
+	odds    *betting.Odds
+	servers *servercheck.Checker
+}
+
+// openServices loads the bot configuration and every store the commands use.
+func openServices() (*services, error) {
+	bot, err := config.LoadBot()
+	if err != nil {
+		return nil, err
+	}
+	guilds, err := config.OpenGuilds()
+	if err != nil {
+		return nil, err
+	}
+	market, err := steammarket.New(bot.MarketConfig(), func(guildID string) steammarket.Defaults {
+		g := guilds.Get(guildID)
+		return steammarket.Defaults{AppID: g.MarketAppID, Currency: g.MarketCurrency}
+	})
+	if err != nil {
+		return nil, err
+	}
+	odds, err := betting.New(bot.OddsConfig(), func(guildID string) betting.Defaults {
+		g := guilds.Get(guildID)
+		return betting.Defaults{Sport: g.OddsSport, Region: g.OddsRegion, Market: g.OddsMarket}
+	})
+	if err != nil {
+		return nil, err
+	}
+	servers, err := servercheck.New(bot.ServersConfig())
+	if err != nil {
+		return nil, err
+	}
+	return &services{bot: bot, guilds: guilds, market: market, odds: odds, servers: servers}, nil
+}
+
+// startJobs runs the background loops until ctx is cancelled. The returned
+// function waits for them all to return.
+func (s *services) startJobs(ctx context.Context, session router.Session) (wait func()) {
+	jobs := []func(ctx context.Context){
+		s.market.RunSampler,
+		func(ctx context.Context) { s.market.RunWatcher(ctx, session) },
+		func(ctx context.Context) { s.odds.RunSnapshots(ctx, session) },
+		func(ctx context.Context) { s.odds.RunSettlement(ctx, session) },
+	}
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(ctx)
+		}()
+	}
+	return wg.Wait
+}
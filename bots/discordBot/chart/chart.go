@@ -0,0 +1,191 @@
+// Package chart draws simple line charts as PNGs using only the standard library.
+package chart
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"time"
+)
+
+const (
+	marginLeft   = 64
+	marginRight  = 20
+	marginTop    = 28
+	marginBottom = 28
+	// ticks is how many labelled grid lines each axis gets.
+	ticks = 5
+)
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	axisColor  = color.RGBA{0x44, 0x44, 0x44, 0xff}
+	gridColor  = color.RGBA{0xe4, 0xe4, 0xe4, 0xff}
+	labelColor = color.RGBA{0x22, 0x22, 0x22, 0xff}
+)
+
+// ErrNoData is returned when there are no points to draw.
+var ErrNoData = errors.New("chart: no data")
+
+// Point is one value at one time.
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is one line on the chart. Points must be in time order.
+type Series struct {
+	Name   string
+	Color  color.RGBA
+	Points []Point
+}
+
+// Line draws series as a width x height line chart and writes it to w as a PNG.
+func Line(w io.Writer, width, height int, series []Series) error {
+	var (
+		first, last time.Time
+		low, high   float64
+		found       bool
+	)
+	for _, s := range series {
+		for _, p := range s.Points {
+			if !found {
+				first, last, low, high, found = p.Time, p.Time, p.Value, p.Value, true
+				continue
+			}
+			first, last = minTime(first, p.Time), maxTime(last, p.Time)
+			low, high = min(low, p.Value), max(high, p.Value)
+		}
+	}
+	if !found {
+		return ErrNoData
+	}
+	if !last.After(first) {
+		first, last = first.Add(-time.Hour), last.Add(time.Hour)
+	}
+	pad := (high - low) * 0.05
+	if pad == 0 {
+		pad = max(high*0.05, 0.01)
+	}
+	low, high = low-pad, high+pad
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+	plot := image.Rect(marginLeft, marginTop, width-marginRight, height-marginBottom)
+
+	x := func(t time.Time) int {
+		return plot.Min.X + int(float64(plot.Dx())*float64(t.Sub(first))/float64(last.Sub(first)))
+	}
+	y := func(v float64) int {
+		return plot.Max.Y - int(float64(plot.Dy())*(v-low)/(high-low))
+	}
+
+	timeFormat := "02/01"
+	if last.Sub(first) < 48*time.Hour {
+		timeFormat = "15:04"
+	}
+	for i := 0; i <= ticks; i++ {
+		v := low + (high-low)*float64(i)/ticks
+		gy := y(v)
+		hline(img, plot.Min.X, plot.Max.X, gy, gridColor)
+		label := fmt.Sprintf("%.2f", v)
+		drawText(img, plot.Min.X-6-textWidth(label), gy-glyphHeight/2, label, labelColor)
+
+		t := first.Add(time.Duration(float64(last.Sub(first)) * float64(i) / ticks))
+		gx := x(t)
+		vline(img, gx, plot.Min.Y, plot.Max.Y, gridColor)
+		label = t.Format(timeFormat)
+		lx := min(max(gx-textWidth(label)/2, 0), width-textWidth(label))
+		drawText(img, lx, plot.Max.Y+8, label, labelColor)
+	}
+	hline(img, plot.Min.X, plot.Max.X, plot.Max.Y, axisColor)
+	vline(img, plot.Min.X, plot.Min.Y, plot.Max.Y, axisColor)
+
+	legendX := plot.Min.X
+	for _, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		fill(img, image.Rect(legendX, 10, legendX+10, 10+glyphHeight), s.Color)
+		drawText(img, legendX+14, 10, s.Name, labelColor)
+		legendX += 14 + textWidth(s.Name) + 16
+
+		prev := image.Pt(x(s.Points[0].Time), y(s.Points[0].Value))
+		fill(img, image.Rect(prev.X-1, prev.Y-1, prev.X+2, prev.Y+2), s.Color)
+		for _, p := range s.Points[1:] {
+			next := image.Pt(x(p.Time), y(p.Value))
+			line(img, prev, next, s.Color)
+			prev = next
+		}
+	}
+	return png.Encode(w, img)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+func hline(img *image.RGBA, x0, x1, y int, c color.Color) {
+	fill(img, image.Rect(x0, y, x1+1, y+1), c)
+}
+
+func vline(img *image.RGBA, x, y0, y1 int, c color.Color) {
+	fill(img, image.Rect(x, y0, x+1, y1+1), c)
+}
+
+// line draws a two pixel thick line from a to b with Bresenham's algorithm.
+func line(img *image.RGBA, a, b image.Point, c color.Color) {
+	dx, dy := abs(b.X-a.X), -abs(b.Y-a.Y)
+	sx, sy := sign(b.X-a.X), sign(b.Y-a.Y)
+	e := dx + dy
+	for {
+		fill(img, image.Rect(a.X, a.Y, a.X+2, a.Y+2), c)
+		if a == b {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			a.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			a.Y += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
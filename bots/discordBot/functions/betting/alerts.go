@@ -0,0 +1,263 @@
+package MatchOdds
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"discordBot/bot/router"
+	"discordBot/store"
+	"discordBot/util"
+)
+
+const alertFile = "oddsalerts.json"
+
+// Alert is a server's subscription to price move alerts on one feed.
+type Alert struct {
+	ChannelID string `json:"channel_id"`
+	Feed
+	// Threshold replaces Config.MoveThreshold for this server when set.
+	Threshold float64 `json:"threshold,omitempty"`
+}
+
+// Alerts stores each server's Alert by guild ID.
+type Alerts struct {
+	file *store.File[map[string]Alert]
+}
+
+// OpenAlerts loads oddsalerts.json from the data directory.
+func OpenAlerts() (*Alerts, error) {
+	file, err := store.Open(alertFile, make(map[string]Alert))
+	if err != nil {
+		return nil, err
+	}
+	return &Alerts{file: file}, nil
+}
+
+// Set replaces guildID's alert subscription.
+func (a *Alerts) Set(guildID string, alert Alert) error {
+	return a.file.Update(func(all *map[string]Alert) error {
+		(*all)[guildID] = alert
+		return nil
+	})
+}
+
+// Remove turns guildID's alerts off, reporting whether they were on.
+func (a *Alerts) Remove(guildID string) (bool, error) {
+	var found bool
+	err := a.file.Update(func(all *map[string]Alert) error {
+		_, found = (*all)[guildID]
+		delete(*all, guildID)
+		return nil
+	})
+	return found, err
+}
+
+// All returns every server's subscription by guild ID.
+func (a *Alerts) All() map[string]Alert {
+	out := make(map[string]Alert)
+	a.file.View(func(all *map[string]Alert) {
+		for guildID, alert := range *all {
+			out[guildID] = alert
+		}
+	})
+	return out
+}
+
+// sentAlerts remembers which moves each server has been told about, so a
+// price that stays moved isn't alerted on again every snapshot.
+type sentAlerts struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// claim reports whether id hasn't been alerted on within window, and marks it
+// alerted if so.
+func (s *sentAlerts) claim(id string, now time.Time, window time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sent == nil {
+		s.sent = make(map[string]time.Time)
+	}
+	if last, ok := s.sent[id]; ok && now.Sub(last) < window {
+		return false
+	}
+	for old, at := range s.sent {
+		if now.Sub(at) >= window {
+			delete(s.sent, old)
+		}
+	}
+	s.sent[id] = now
+	return true
+}
+
+// feeds lists the tracked feeds and every feed a server has alerts on, once each.
+func (o *Odds) feeds(alerts map[string]Alert) []Feed {
+	var out []Feed
+	for _, f := range o.cfg.Tracked {
+		if f = f.withDefaults(); !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	for _, a := range alerts {
+		if f := a.Feed.withDefaults(); !slices.Contains(out, f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// RunSnapshots snapshots every tracked or alerted feed every SnapshotEvery and
+// posts big price moves to the servers that asked for them. It returns when
+// ctx is cancelled.
+func (o *Odds) RunSnapshots(ctx context.Context, session router.Session) {
+	logger := util.LoggerInit("BETTING", "Snapshots")
+	every := time.Duration(o.cfg.SnapshotEvery)
+	if every <= 0 {
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		alerts := o.alerts.All()
+		for _, feed := range o.feeds(alerts) {
+			matches, err := o.api.GetOdds(ctx, feed.Sport, feed.Region, feed.Market)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				logger.Warn("Failed to snapshot odds", "sport", feed.Sport, "error", err)
+				continue
+			}
+			keys, err := o.snapshots.Record(feed, NewBoards(matches, feed.Market), time.Now())
+			if err != nil {
+				logger.Error("Failed to save odds snapshots", "error", err)
+				continue
+			}
+			o.alertMoves(session, feed, alerts, o.snapshots.Moves(keys, time.Duration(o.cfg.MoveWindow)))
+		}
+	}
+}
+
+// alertMoves posts every move on feed past a server's threshold to its alert channel.
+func (o *Odds) alertMoves(session router.Session, feed Feed, alerts map[string]Alert, moves []Move) {
+	logger := util.LoggerInit("BETTING", "Alerts")
+	window := time.Duration(o.cfg.MoveWindow)
+	now := time.Now()
+	for guildID, alert := range alerts {
+		if alert.Feed.withDefaults() != feed {
+			continue
+		}
+		threshold := alert.Threshold
+		if threshold <= 0 {
+			threshold = o.cfg.MoveThreshold
+		}
+		var lines []string
+		for _, m := range moves {
+			if math.Abs(m.Change())*100 < threshold || !o.sent.claim(guildID+"/"+m.Key+"/"+m.Outcome, now, window) {
+				continue
+			}
+			lines = append(lines, moveLine(m))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		header := fmt.Sprintf("**Odds moves in %s** (%s, %s)", feed.Sport, feed.Region, feed.Market)
+		for _, msg := range router.SplitMessage(header+"\n"+strings.Join(lines, "\n"), router.MessageLimit) {
+			if _, err := session.ChannelMessageSend(alert.ChannelID, msg); err != nil {
+				logger.Warn("Could not post odds alert", "guild", guildID, "channel", alert.ChannelID, "error", err)
+				break
+			}
+		}
+	}
+}
+
+// moveLine describes a move, e.g. "📉 **Arsenal vs Spurs**: Spurs shortened
+// from 4.00 to 3.40 (-15.0%) since 2 hours ago".
+func moveLine(m Move) string {
+	icon, verb := "📈", "drifted"
+	if m.Change() < 0 {
+		icon, verb = "📉", "shortened"
+	}
+	return fmt.Sprintf("%s **%s**: %s %s from %.2f to %.2f (%+.1f%%) since <t:%d:R>",
+		icon, m.Series.Title(), m.Outcome, verb, m.From, m.To, m.Change()*100, m.Since.Unix())
+}
+
+func (o *Odds) setAlerts(req *router.Request) {
+	if req.GuildID == "" {
+		req.Reply("Odds alerts can only be set up in a server.")
+		return
+	}
+	action, channelID, sport, threshold := req.Arg(0), req.Arg(1), req.Arg(2), req.Arg(3)
+	if action == "off" {
+		found, err := o.alerts.Remove(req.GuildID)
+		if err != nil {
+			req.Reply("Failed to turn off odds alerts: " + err.Error())
+			return
+		}
+		if !found {
+			req.Reply("Odds alerts weren't on in this server.")
+			return
+		}
+		req.Reply("Odds alerts are off.")
+		return
+	}
+	if action != "on" {
+		req.Reply(req.Command.UsageText(req.Prefix()))
+		return
+	}
+	if channelID == "" {
+		channelID = req.ChannelID
+	}
+	channelID = strings.TrimSuffix(strings.TrimPrefix(channelID, "<#"), ">")
+	if channel, err := req.Session.Channel(channelID); err != nil || channel.GuildID != req.GuildID {
+		req.Reply("`" + channelID + "` isn't a channel in this server.")
+		return
+	}
+	d := o.defaults(req, Defaults{Sport: sport})
+	if err := o.sports.check(req.Context(), d.Sport, d.Market); err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	alert := Alert{ChannelID: channelID, Feed: Feed{Sport: d.Sport, Region: d.Region, Market: d.Market}}
+	if threshold != "" {
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(threshold, "%"), 64)
+		if err != nil || pct <= 0 {
+			req.Reply("`" + threshold + "` isn't a percentage. Use a number like 10.")
+			return
+		}
+		alert.Threshold = pct
+	}
+	if err := o.alerts.Set(req.GuildID, alert); err != nil {
+		req.Reply("Failed to save odds alerts: " + err.Error())
+		return
+	}
+	pct := alert.Threshold
+	if pct == 0 {
+		pct = o.cfg.MoveThreshold
+	}
+	req.Reply(fmt.Sprintf("I'll post in <#%s> when a best price on %s moves %g%% or more within %s. Odds are checked every %s.",
+		channelID, d.Sport, pct, shortDuration(time.Duration(o.cfg.MoveWindow)), shortDuration(time.Duration(o.cfg.SnapshotEvery))))
+}
+
+// shortDuration drops the zero units time.Duration prints, e.g. "3h" rather than "3h0m0s".
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,227 @@
+package MatchOdds
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"discordBot/bot/router"
+	the_odds "discordBot/functions/betting/api"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// arbBankroll is the total the stake split is shown for.
+	arbBankroll = 100
+	// minConsensus is how many bookmakers have to price a line before their
+	// consensus is trusted to spot value.
+	minConsensus = 3
+)
+
+// Arb is a line whose best prices add up to under 100%: backing every outcome
+// at its best price in proportion to its implied probability returns the
+// same profit whichever wins.
+type Arb struct {
+	Board Board
+	Line  Line
+}
+
+// Profit is the guaranteed return on the total staked, as a fraction.
+func (a Arb) Profit() float64 {
+	return 1/(1+a.Line.Overround()) - 1
+}
+
+// Stakes splits total across the line's outcomes so every one returns the same.
+func (a Arb) Stakes(total float64) []float64 {
+	sum := 1 + a.Line.Overround()
+	stakes := make([]float64, len(a.Line.Best))
+	for i, best := range a.Line.Best {
+		stakes[i] = total * best.Implied() / sum
+	}
+	return stakes
+}
+
+// FindArbs lists every line on boards with a guaranteed profit, biggest first.
+// Lines with a single outcome priced can't be covered, so they're skipped.
+func FindArbs(boards []Board) []Arb {
+	var arbs []Arb
+	for _, board := range boards {
+		for _, line := range board.Lines() {
+			if len(line.Best) >= 2 && line.Overround() < 0 {
+				arbs = append(arbs, Arb{Board: board, Line: line})
+			}
+		}
+	}
+	sort.SliceStable(arbs, func(i, j int) bool {
+		return arbs[i].Profit() > arbs[j].Profit()
+	})
+	return arbs
+}
+
+// Value is one bookmaker's price that beats the fair price, the consensus of
+// every bookmaker once their margins are taken out.
+type Value struct {
+	Board     Board
+	Bookmaker string
+	Outcome   Best
+	// Fair is the price the no-vig consensus probability implies.
+	Fair float64
+	// Books is how many bookmakers make up the consensus.
+	Books int
+}
+
+// Edge is how much the price beats the fair price by, as a fraction: the
+// expected return on a stake if the consensus is right.
+func (v Value) Edge() float64 {
+	return v.Outcome.Price/v.Fair - 1
+}
+
+// FindValue lists every bookmaker price on market at least threshold (a
+// fraction) above the no-vig consensus, biggest edge first. Each bookmaker's
+// implied probabilities on a line are scaled to add up to 1, removing its
+// margin, and averaged across bookmakers to get the consensus.
+func FindValue(matches []the_odds.Match, market string, threshold float64) []Value {
+	var values []Value
+	for _, match := range matches {
+		board := NewBoard(match, market)
+		if len(board.Best) == 0 {
+			continue
+		}
+		// fair sums each outcome's no-vig probability across bookmakers, and
+		// books counts the bookmakers pricing each line.
+		fair := make(map[string]float64)
+		books := make(map[float64]int)
+		for _, bookmaker := range match.Bookmakers {
+			for line, outcomes := range bookmakerLines(board, bookmaker, market) {
+				if len(outcomes) < 2 {
+					continue
+				}
+				total := 0.0
+				for _, o := range outcomes {
+					total += 1 / o.Price
+				}
+				for _, o := range outcomes {
+					fair[bestOf(o).Label()] += 1 / o.Price / total
+				}
+				books[line]++
+			}
+		}
+		for _, bookmaker := range match.Bookmakers {
+			for line, outcomes := range bookmakerLines(board, bookmaker, market) {
+				n := books[line]
+				if n < minConsensus || len(outcomes) < 2 {
+					continue
+				}
+				for _, o := range outcomes {
+					best := bestOf(o)
+					v := Value{Board: board, Bookmaker: bookmaker.Title, Outcome: best, Fair: float64(n) / fair[best.Label()], Books: n}
+					if v.Edge() >= threshold {
+						values = append(values, v)
+					}
+				}
+			}
+		}
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		return values[i].Edge() > values[j].Edge()
+	})
+	return values
+}
+
+// bookmakerLines groups a bookmaker's priced outcomes on market by line.
+func bookmakerLines(board Board, bookmaker the_odds.Bookmaker, market string) map[float64][]the_odds.Outcome {
+	lines := make(map[float64][]the_odds.Outcome)
+	for _, m := range bookmaker.Markets {
+		if m.Key != market {
+			continue
+		}
+		for _, o := range m.Outcomes {
+			if o.Price <= 1 {
+				continue
+			}
+			point, _ := board.linePoint(bestOf(o))
+			lines[point] = append(lines[point], o)
+		}
+	}
+	return lines
+}
+
+func bestOf(o the_odds.Outcome) Best {
+	return Best{Outcome: o.Name, Point: o.Point, Price: o.Price}
+}
+
+func (o *Odds) arb(req *router.Request) {
+	d := o.defaults(req, Defaults{Sport: req.Arg(0), Region: req.Arg(1), Market: req.Arg(2)})
+	matches, ok := o.fetch(req, d)
+	if !ok {
+		return
+	}
+	arbs := FindArbs(NewBoards(matches, d.Market))
+	if len(arbs) == 0 {
+		req.Reply(fmt.Sprintf("No arbitrage in %s %s odds from %s bookmakers right now: every line's best prices add up to 100%% or more.", d.Sport, d.Market, d.Region))
+		return
+	}
+	pages := make([]*discordgo.MessageEmbed, len(arbs))
+	for i, a := range arbs {
+		pages[i] = arbEmbed(a)
+	}
+	req.ReplyPages(pages)
+}
+
+// arbEmbed shows an arbitrage: each outcome's best price, who offers it and
+// its share of a 100 stake.
+func arbEmbed(a Arb) *discordgo.MessageEmbed {
+	stakes := a.Stakes(arbBankroll)
+	fields := make([]*discordgo.MessageEmbedField, len(a.Line.Best))
+	for i, best := range a.Line.Best {
+		fields[i] = &discordgo.MessageEmbedField{
+			Name:   best.Label(),
+			Value:  fmt.Sprintf("**%.2f** at %s\nStake %.2f", best.Price, bookmakerList(best.Bookmakers), stakes[i]),
+			Inline: true,
+		}
+	}
+	description := fmt.Sprintf("Staking %d as below returns %.2f whichever outcome wins, a %.2f%% profit.",
+		arbBankroll, arbBankroll*(1+a.Profit()), a.Profit()*100)
+	if kickoff := a.Board.Kickoff(); !kickoff.IsZero() {
+		description += fmt.Sprintf(" Kick-off <t:%d:R>.", kickoff.Unix())
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "Arbitrage: " + a.Board.Title(),
+		Description: description,
+		Color:       0x00ffcc,
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Prices move and bookmakers limit stakes, so check every price before betting",
+		},
+	}
+}
+
+func (o *Odds) value(req *router.Request) {
+	d := o.defaults(req, Defaults{Sport: req.Arg(0), Region: req.Arg(1), Market: req.Arg(2)})
+	threshold := o.cfg.ValueThreshold
+	if arg := req.Arg(3); arg != "" {
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(arg, "%"), 64)
+		if err != nil || pct <= 0 {
+			req.Reply("`" + arg + "` isn't a percentage. Use a number like 5.")
+			return
+		}
+		threshold = pct
+	}
+	matches, ok := o.fetch(req, d)
+	if !ok {
+		return
+	}
+	values := FindValue(matches, d.Market, threshold/100)
+	if len(values) == 0 {
+		req.Reply(fmt.Sprintf("No %s %s price from %s bookmakers beats the consensus by %g%% or more right now.", d.Sport, d.Market, d.Region, threshold))
+		return
+	}
+	lines := make([]string, len(values))
+	for i, v := range values {
+		lines[i] = fmt.Sprintf("**%s** in %s: **%.2f** at %s, fair %.2f, edge **%+.1f%%** (%d bookmakers)",
+			v.Outcome.Label(), v.Board.Title(), v.Outcome.Price, v.Bookmaker, v.Fair, v.Edge()*100, v.Books)
+	}
+	req.ReplyPages(router.Paginate(fmt.Sprintf("Value Bets: %s %s (%s)", d.Sport, d.Market, d.Region), lines, 10))
+}
@@ -0,0 +1,130 @@
+package MatchOdds
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"slices"
+	"testing"
+
+	the_odds "discordBot/functions/betting/api"
+)
+
+// loadOdds reads an /odds response saved in testdata. odds_h2h.json has an
+// arb on Arsenal vs Spurs, a value price on the Lakers at Dash Sports, and a
+// bigger outlier on the Heat that only two bookmakers price.
+func loadOdds(t *testing.T, name string) []the_odds.Match {
+	t.Helper()
+	raw, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matches []the_odds.Match
+	if err := json.Unmarshal(raw, &matches); err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestFindArbs(t *testing.T) {
+	arbs := FindArbs(NewBoards(loadOdds(t, "odds_h2h.json"), "h2h"))
+	if len(arbs) != 1 {
+		t.Fatalf("found %d arbs, want only Arsenal vs Spurs", len(arbs))
+	}
+	a := arbs[0]
+	if got := a.Board.Title(); got != "Arsenal vs Spurs" {
+		t.Fatalf("arb on %q, want Arsenal vs Spurs", got)
+	}
+
+	// Best prices: Arsenal 2.10 at Alpha Bet, Draw 3.90 at Betto, Spurs 4.60
+	// at Coral Reef, which add up to 94.9992%.
+	var prices []float64
+	for _, best := range a.Line.Best {
+		prices = append(prices, best.Price)
+	}
+	if !slices.Equal(prices, []float64{2.10, 3.90, 4.60}) {
+		t.Errorf("best prices = %v, want home, draw, away at 2.10, 3.90, 4.60", prices)
+	}
+	sum := 1/2.10 + 1/3.90 + 1/4.60
+	if got, want := a.Profit(), 1/sum-1; !near(got, want) || !near(got, 0.0526404) {
+		t.Errorf("Profit() = %v, want %v", got, want)
+	}
+
+	stakes := a.Stakes(arbBankroll)
+	want := []float64{50.125733, 26.990780, 22.883487}
+	total := 0.0
+	for i, stake := range stakes {
+		total += stake
+		if !near(stake, want[i]) {
+			t.Errorf("stake on %s = %v, want %v", a.Line.Best[i].Outcome, stake, want[i])
+		}
+		// Whichever outcome wins, the bankroll comes back with the profit.
+		if got := stake * a.Line.Best[i].Price; !near(got, arbBankroll*(1+a.Profit())) {
+			t.Errorf("%s returns %v, want %v like the others", a.Line.Best[i].Outcome, got, arbBankroll*(1+a.Profit()))
+		}
+	}
+	if !near(total, arbBankroll) {
+		t.Errorf("stakes add up to %v, want %d", total, arbBankroll)
+	}
+}
+
+func TestFindValue(t *testing.T) {
+	values := FindValue(loadOdds(t, "odds_h2h.json"), "h2h", 0.05)
+	type found struct {
+		bookmaker, outcome string
+		price, fair        float64
+		books              int
+	}
+	// The Heat at 3.00 beats its consensus by 6.9%, but with two bookmakers
+	// pricing it that's short of minConsensus and isn't listed.
+	want := []found{
+		{"Coral Reef", "Spurs", 4.60, 4.163012, 3},
+		{"Dash Sports", "Los Angeles Lakers", 2.50, 2.352357, 4},
+		{"Betto", "Draw", 3.90, 3.704273, 3},
+	}
+	if len(values) != len(want) {
+		t.Fatalf("found %d value prices, want %d: %+v", len(values), len(want), values)
+	}
+	for i, v := range values {
+		w := want[i]
+		if v.Bookmaker != w.bookmaker || v.Outcome.Outcome != w.outcome || v.Outcome.Price != w.price || v.Books != w.books || !near(v.Fair, w.fair) {
+			t.Errorf("value %d = %s %s %.2f fair %.6f from %d books, want %s %s %.2f fair %.6f from %d books",
+				i, v.Bookmaker, v.Outcome.Outcome, v.Outcome.Price, v.Fair, v.Books, w.bookmaker, w.outcome, w.price, w.fair, w.books)
+		}
+		if got := v.Edge(); !near(got, w.price/w.fair-1) {
+			t.Errorf("%s edge = %v, want %v", w.outcome, got, w.price/w.fair-1)
+		}
+	}
+
+	// The Lakers' fair price by hand: each bookmaker's margin taken out, then
+	// averaged. Three price them at 2.00/1.60, Dash Sports at 2.50/1.45.
+	even := (1 / 2.00) / (1/2.00 + 1/1.60)
+	dash := (1 / 2.50) / (1/2.50 + 1/1.45)
+	if fair := 4 / (3*even + dash); !near(values[1].Fair, fair) {
+		t.Errorf("Lakers fair = %v, want %v", values[1].Fair, fair)
+	}
+}
+
+func TestFindValueNeedsConsensus(t *testing.T) {
+	matches := loadOdds(t, "odds_h2h.json")
+	var heat []the_odds.Match
+	for _, m := range matches {
+		if m.HomeTeam == "Miami Heat" {
+			heat = append(heat, m)
+		}
+	}
+	if got := FindValue(heat, "h2h", 0.05); len(got) != 0 {
+		t.Errorf("two bookmakers made a consensus: %+v", got)
+	}
+
+	// A third bookmaker agreeing with Alpha Bet is enough to trust it.
+	third := heat[0].Bookmakers[0]
+	third.Title = "Third"
+	heat[0].Bookmakers = append(heat[0].Bookmakers, third)
+	got := FindValue(heat, "h2h", 0.05)
+	if len(got) != 1 || got[0].Bookmaker != "Betto" || got[0].Outcome.Outcome != "Miami Heat" || got[0].Books != minConsensus {
+		t.Errorf("with %d bookmakers found %+v, want Betto's Heat price", minConsensus, got)
+	}
+}
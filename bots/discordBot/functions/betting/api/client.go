@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	loggerInit "discordBot/util"
+)
+
+const (
+	// DefaultBaseURL is The Odds API v4 root.
+	DefaultBaseURL = "https://api.the-odds-api.com/v4"
+	// requestTimeout bounds one upstream request. Coalesced callers share it,
+	// so it isn't tied to any one caller's context.
+	requestTimeout = 15 * time.Second
+)
+
+// Errors shown in place of the underlying ones, which can carry the signed
+// request URL and with it the API key. The details are logged, redacted.
+var (
+	ErrUnreachable = errors.New("couldn't reach the odds API, try again later")
+	ErrBadResponse = errors.New("unexpected response from the odds API")
+)
+
+// QuotaError is returned instead of calling The Odds API when the requests
+// left this month have dropped below the reserve.
+type QuotaError struct {
+	Remaining, Reserve int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("only %d Odds API requests are left this month and the last %d are held in reserve, try again after the quota resets", e.Remaining, e.Reserve)
+}
+
+// Quota is what The Odds API last said about our usage, from its
+// x-requests-* response headers.
+type Quota struct {
+	// Known is false until the first response comes back.
+	Known     bool
+	Remaining int
+	Used      int
+	// LastCost is how many requests the last call used up.
+	LastCost int
+	Updated  time.Time
+}
+
+// Options configures a Client. Zero values leave caching off and no reserve.
+type Options struct {
+	// BaseURL replaces DefaultBaseURL, e.g. to test against a local server.
+	BaseURL  string
+	CacheTTL time.Duration
+	// Reserve is how many requests are kept back: metered calls are refused
+	// once fewer than this many are left.
+	Reserve int
+}
+
+type cached struct {
+	body    []byte
+	fetched time.Time
+}
+
+// call is an upstream request other callers for the same URL can wait on.
+type call struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// Client calls The Odds API with the THE_ODDS key. Responses are cached for
+// CacheTTL, identical requests in flight are made only once, and the quota
+// headers are tracked so the reserve can be kept.
+type Client struct {
+	opts Options
+	http *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cached
+	calls map[string]*call
+	quota Quota
+}
+
+// NewClient makes a Client.
+func NewClient(opts Options) *Client {
+	if opts.BaseURL == "" {
+		opts.BaseURL = DefaultBaseURL
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	return &Client{
+		opts:  opts,
+		http:  &http.Client{Timeout: requestTimeout},
+		cache: make(map[string]cached),
+		calls: make(map[string]*call),
+	}
+}
+
+// Quota returns what The Odds API last said about our usage.
+func (c *Client) Quota() Quota {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.quota
+}
+
+// Reserve is how many requests are held back.
+func (c *Client) Reserve() int {
+	return c.opts.Reserve
+}
+
+// get calls path and decodes the JSON response into out. metered calls count
+// against the quota, so they're refused when it's down to the reserve.
+func (c *Client) get(ctx context.Context, path string, query url.Values, metered bool, out any) error {
+	logger := loggerInit.LoggerInit("API", "THE_ODDS_API")
+	body, err := c.fetch(ctx, path, query, metered)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		logger.Error("Failed to parse JSON: ", "path", path, "error", err)
+		return ErrBadResponse
+	}
+	return nil
+}
+
+// fetch returns the body for path and query from the cache, from a request
+// already in flight, or from a new request. Giving up on ctx doesn't cancel
+// the request, so other callers waiting on it still get the answer.
+func (c *Client) fetch(ctx context.Context, path string, query url.Values, metered bool) ([]byte, error) {
+	key := path + "?" + query.Encode()
+	c.mu.Lock()
+	if entry, ok := c.cache[key]; ok && time.Since(entry.fetched) < c.opts.CacheTTL {
+		c.mu.Unlock()
+		return entry.body, nil
+	}
+	pending, ok := c.calls[key]
+	if !ok {
+		if metered && c.quota.Known && c.quota.Remaining < c.opts.Reserve {
+			c.mu.Unlock()
+			return nil, &QuotaError{Remaining: c.quota.Remaining, Reserve: c.opts.Reserve}
+		}
+		pending = &call{done: make(chan struct{})}
+		c.calls[key] = pending
+		go c.run(key, path, query, pending)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-pending.done:
+		return pending.body, pending.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// run makes the request for pending and caches what comes back.
+func (c *Client) run(key, path string, query url.Values, pending *call) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	body, err := c.request(ctx, path, query)
+	cancel()
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	if err == nil && c.opts.CacheTTL > 0 {
+		now := time.Now()
+		for k, entry := range c.cache {
+			if now.Sub(entry.fetched) >= c.opts.CacheTTL {
+				delete(c.cache, k)
+			}
+		}
+		c.cache[key] = cached{body: body, fetched: now}
+	}
+	pending.body, pending.err = body, err
+	c.mu.Unlock()
+	close(pending.done)
+}
+
+// request makes one call to The Odds API and records the quota headers.
+func (c *Client) request(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	logger := loggerInit.LoggerInit("API", "THE_ODDS_API")
+	token := os.Getenv("THE_ODDS")
+	if len(token) == 0 {
+		logger.Error("Token length == 0!")
+		return nil, errors.New("THE_ODDS is not set")
+	}
+	signed := url.Values{}
+	for k, v := range query {
+		signed[k] = v
+	}
+	signed.Set("apiKey", token)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.opts.BaseURL+path+"?"+signed.Encode(), nil)
+	if err != nil {
+		logger.Error("Failed to build request", "path", path, "error", redact(err, token))
+		return nil, ErrUnreachable
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		logger.Error("Failed to get request", "path", path, "error", redact(err, token))
+		return nil, ErrUnreachable
+	}
+	defer response.Body.Close()
+	c.recordQuota(response.Header)
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		logger.Error("Failed to read response body", "path", path, "error", redact(err, token))
+		return nil, ErrUnreachable
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusUnauthorized:
+		return nil, errors.New("the odds API rejected the THE_ODDS key, or its quota is used up")
+	case http.StatusTooManyRequests:
+		return nil, errors.New("the odds API is rate limiting us, try again in a minute")
+	}
+	logger.Error("The Odds API returned an error", "status", response.StatusCode, "body", string(body))
+	return nil, fmt.Errorf("the odds API answered HTTP %d", response.StatusCode)
+}
+
+// redact takes the API key out of err's text. A *url.Error names the whole
+// signed URL, so the key is dropped from its query; anything else has the key
+// blanked out wherever it appears.
+func redact(err error, token string) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			query := u.Query()
+			query.Del("apiKey")
+			u.RawQuery = query.Encode()
+			err = &url.Error{Op: urlErr.Op, URL: u.String(), Err: urlErr.Err}
+		}
+	}
+	text := err.Error()
+	for _, key := range []string{token, url.QueryEscape(token)} {
+		text = strings.ReplaceAll(text, key, "REDACTED")
+	}
+	return text
+}
+
+// recordQuota updates the quota from a response's x-requests-* headers.
+func (c *Client) recordQuota(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("x-requests-remaining"))
+	if err != nil {
+		return
+	}
+	used, _ := strconv.Atoi(header.Get("x-requests-used"))
+	last, _ := strconv.Atoi(header.Get("x-requests-last"))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.quota = Quota{Known: true, Remaining: remaining, Used: used, LastCost: last, Updated: time.Now()}
+}
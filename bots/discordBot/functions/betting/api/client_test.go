@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testKey = "k3y+with/odd=chars"
+
+func TestRequestErrorsDontCarryTheKey(t *testing.T) {
+	t.Setenv("THE_ODDS", testKey)
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	c := NewClient(Options{BaseURL: srv.URL})
+	_, err := c.GetSports(context.Background(), false)
+	if !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("error = %v, want %v", err, ErrUnreachable)
+	}
+
+	// The transport error itself names the signed URL; only the redacted
+	// text is logged.
+	request, _ := http.NewRequest(http.MethodGet, srv.URL+"/sports?"+url.Values{"apiKey": {testKey}}.Encode(), nil)
+	_, raw := http.DefaultClient.Do(request)
+	if raw == nil {
+		t.Fatal("request to a closed server succeeded")
+	}
+	if got := redact(raw, testKey); strings.Contains(got, "k3y") || !strings.Contains(got, "/sports") {
+		t.Errorf("redact(%q) = %q, want the URL without the key", raw, got)
+	}
+	if got := redact(errors.New("bad key "+testKey), testKey); got != "bad key REDACTED" {
+		t.Errorf("redact = %q, want the key blanked out", got)
+	}
+}
+
+func TestBadJSONIsAGenericError(t *testing.T) {
+	t.Setenv("THE_ODDS", testKey)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"not":"a list"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(Options{BaseURL: srv.URL}).GetSports(context.Background(), false)
+	if err != ErrBadResponse {
+		t.Errorf("error = %v, want %v", err, ErrBadResponse)
+	}
+}
+
+func TestCoalescedCallersOutliveTheFirst(t *testing.T) {
+	t.Setenv("THE_ODDS", testKey)
+	var requests atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		fmt.Fprint(w, `[{"key":"soccer_epl","title":"EPL","active":true}]`)
+	}))
+	defer srv.Close()
+	c := NewClient(Options{BaseURL: srv.URL})
+
+	first, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := c.GetSports(first, false)
+		firstErr <- err
+	}()
+	<-started
+
+	second := make(chan error, 1)
+	go func() {
+		sports, err := c.GetSports(context.Background(), false)
+		if err == nil && (len(sports) != 1 || sports[0].Key != "soccer_epl") {
+			err = fmt.Errorf("got %+v", sports)
+		}
+		second <- err
+	}()
+	// Give the second caller time to join the request in flight.
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("first caller's error = %v, want context.Canceled", err)
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Errorf("second caller failed with the first: %v", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("made %d requests, want the callers coalesced into 1", n)
+	}
+}
@@ -1,5 +1,6 @@
 /*
-API_CALLS for /sport/{SPORT}/
+API_CALLS for /sport/{SPORT}/, e.g. the football leagues below. Every sport
+key The Odds API knows is listed by GetSports.
 "soccer_epl" → English Premier League
 
 "soccer_efl_championship" → EFL Championship
@@ -15,26 +16,17 @@ API_CALLS for /sport/{SPORT}/
 package api
 
 import (
-	loggerInit "discordBot/util"
-	"encoding/json"
-	"io"
-	"net/http"
-	"os"
-
-	"github.com/joho/godotenv"
+	"context"
+	"net/url"
+	"strconv"
 )
 
-type MatchOdds struct {
-	HomeTeam     string
-	AwayTeam     string
-	CommenceTime string
-	Bookmaker    string
-	Odds         map[string]float64 // Outcome name -> price
-}
-
 type Outcome struct {
 	Name  string  `json:"name"`
 	Price float64 `json:"price"`
+	// Point is the handicap on spreads and the total on totals. It's nil
+	// on markets without a line, like h2h and outrights.
+	Point *float64 `json:"point,omitempty"`
 }
 
 type Market struct {
@@ -50,6 +42,8 @@ type Bookmaker struct {
 	Markets    []Market `json:"markets"`
 }
 
+// Match is one event's odds. Outrights have no home or away team; the
+// event is the sport itself, e.g. the league winner.
 type Match struct {
 	ID           string      `json:"id"`
 	SportKey     string      `json:"sport_key"`
@@ -60,61 +54,72 @@ type Match struct {
 	Bookmakers   []Bookmaker `json:"bookmakers"`
 }
 
-func GetTheOddsAPI() ([]MatchOdds, error) {
-	logger := loggerInit.LoggerInit("API", "THE_ODDS_API")
-	err := godotenv.Load()
-	if err != nil {
-		logger.Info("INVALID BOT TOKEN", "ERROR", err.Error())
+// Sport is one entry of The Odds API's /sports list.
+type Sport struct {
+	Key          string `json:"key"`
+	Group        string `json:"group"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Active       bool   `json:"active"`
+	HasOutrights bool   `json:"has_outrights"`
+}
+
+// GetSports lists the sports The Odds API covers. Only sports in season are
+// listed unless all is set. This endpoint doesn't count against the quota.
+func (c *Client) GetSports(ctx context.Context, all bool) ([]Sport, error) {
+	query := url.Values{}
+	if all {
+		query.Set("all", "true")
+	}
+	var sports []Sport
+	if err := c.get(ctx, "/sports", query, false, &sports); err != nil {
 		return nil, err
 	}
-	token := os.Getenv("THE_ODDS")
-	if len(token) == 0 {
-		logger.Error("Token length == 0!")
+	return sports, nil
+}
+
+// GetOdds fetches upcoming matches for sport with odds from bookmakers in
+// region for market.
+func (c *Client) GetOdds(ctx context.Context, sport, region, market string) ([]Match, error) {
+	query := url.Values{}
+	query.Set("regions", region)
+	query.Set("markets", market)
+	var matches []Match
+	if err := c.get(ctx, "/sports/"+url.PathEscape(sport)+"/odds", query, true, &matches); err != nil {
 		return nil, err
 	}
+	return matches, nil
+}
 
-	url := "https://api.the-odds-api.com/v4/sports/soccer_epl/odds?apiKey=" + token + "&regions=uk&markets=h2h"
+// TeamScore is one team's score in a Score.
+type TeamScore struct {
+	Name  string `json:"name"`
+	Score string `json:"score"`
+}
 
-	response, err := http.Get(url)
-	if err != nil {
-		logger.Error("Failed to get request", "error", err)
-		os.Exit(1)
-	}
-	defer response.Body.Close()
+// Score is a match from The Odds API's scores endpoint. Scores is empty until
+// the match starts.
+type Score struct {
+	ID           string      `json:"id"`
+	SportKey     string      `json:"sport_key"`
+	CommenceTime string      `json:"commence_time"`
+	Completed    bool        `json:"completed"`
+	HomeTeam     string      `json:"home_team"`
+	AwayTeam     string      `json:"away_team"`
+	Scores       []TeamScore `json:"scores"`
+}
 
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		logger.Error("Failed to read response body", "error", err)
-		return nil, err
+// GetScores fetches live and upcoming matches for sport, and those completed
+// in the last daysFrom days (at most 3). Asking for completed matches costs
+// two requests instead of one.
+func (c *Client) GetScores(ctx context.Context, sport string, daysFrom int) ([]Score, error) {
+	query := url.Values{}
+	if daysFrom > 0 {
+		query.Set("daysFrom", strconv.Itoa(daysFrom))
 	}
-
-	var matches []Match
-	err = json.Unmarshal(body, &matches)
-	if err != nil {
-		logger.Error("Failed to parse JSON: ", "error", err)
+	var scores []Score
+	if err := c.get(ctx, "/sports/"+url.PathEscape(sport)+"/scores", query, true, &scores); err != nil {
 		return nil, err
 	}
-	var results []MatchOdds
-
-	for _, match := range matches {
-		for _, bookmaker := range match.Bookmakers {
-			for _, market := range bookmaker.Markets {
-				if market.Key == "h2h" {
-					odds := make(map[string]float64)
-					for _, outcome := range market.Outcomes {
-						odds[outcome.Name] = outcome.Price
-					}
-					results = append(results, MatchOdds{
-						HomeTeam:     match.HomeTeam,
-						AwayTeam:     match.AwayTeam,
-						CommenceTime: match.CommenceTime,
-						Bookmaker:    bookmaker.Title,
-						Odds:         odds,
-					})
-				}
-			}
-		}
-	}
-
-	return results, nil
+	return scores, nil
 }
@@ -0,0 +1,235 @@
+package MatchOdds
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	the_odds "discordBot/functions/betting/api"
+)
+
+// Best is the best price on offer for one outcome and who offers it.
+type Best struct {
+	Outcome string
+	// Point is the outcome's handicap or total, nil on markets without one.
+	Point      *float64
+	Price      float64
+	Bookmakers []string
+}
+
+// Implied is the probability the price implies, from 0 to 1.
+func (b Best) Implied() float64 {
+	return 1 / b.Price
+}
+
+// Label names the outcome with its line, e.g. "Arsenal", "Arsenal -1.5" or
+// "Over 2.5". Snapshots are keyed by it.
+func (b Best) Label() string {
+	switch {
+	case b.Point == nil:
+		return b.Outcome
+	case b.Outcome == "Over" || b.Outcome == "Under":
+		return fmt.Sprintf("%s %g", b.Outcome, *b.Point)
+	}
+	return fmt.Sprintf("%s %+g", b.Outcome, *b.Point)
+}
+
+// Line is the outcomes that settle against each other: one total, one
+// handicap, or the whole market when it has no points.
+type Line struct {
+	// Point is the total, or the home side's handicap. It's nil on markets
+	// without a line.
+	Point *float64
+	Best  []Best
+	// Margins is each bookmaker's own overround on the line, lowest first,
+	// from the bookmakers that price every outcome of it.
+	Margins []float64
+}
+
+// Overround is how far the best prices' implied probabilities add up past
+// 100%, as a fraction. It's the margin left in the line once you shop
+// around, not what any one bookmaker charges; below zero, backing every
+// outcome at its best price guarantees a profit.
+func (l Line) Overround() float64 {
+	if len(l.Best) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, best := range l.Best {
+		total += best.Implied()
+	}
+	return total - 1
+}
+
+// MedianMargin is the middle of the bookmakers' own overrounds, or the mean
+// of the middle two. ok is false when no bookmaker prices the whole line.
+func (l Line) MedianMargin() (median float64, ok bool) {
+	n := len(l.Margins)
+	if n == 0 {
+		return 0, false
+	}
+	if n%2 == 1 {
+		return l.Margins[n/2], true
+	}
+	return (l.Margins[n/2-1] + l.Margins[n/2]) / 2, true
+}
+
+// Board is one match's odds on one market, across every bookmaker.
+type Board struct {
+	Match the_odds.Match
+	// Market is the market key the prices are for, e.g. h2h.
+	Market string
+	// Best lists each outcome's best price: home, draw, away, over, under,
+	// then the rest shortest first, each by line.
+	Best []Best
+	// Bookmakers is how many bookmakers price the market.
+	Bookmakers int
+}
+
+// Title is "Home vs Away", or the sport for outrights.
+func (b Board) Title() string {
+	if b.Outright() {
+		return b.Match.SportTitle
+	}
+	return b.Match.HomeTeam + " vs " + b.Match.AwayTeam
+}
+
+// Outright reports whether the board is a futures market with no teams
+// playing each other, like a league winner.
+func (b Board) Outright() bool {
+	return b.Match.HomeTeam == "" && b.Match.AwayTeam == ""
+}
+
+// Kickoff parses the match's commence time. It's zero if The Odds API sent
+// something unexpected.
+func (b Board) Kickoff() time.Time {
+	t, _ := time.Parse(time.RFC3339, b.Match.CommenceTime)
+	return t
+}
+
+// linePoint is the point that identifies the line best belongs to. An away
+// handicap of +1.5 is the same line as a home handicap of -1.5.
+func (b Board) linePoint(best Best) (float64, bool) {
+	if best.Point == nil {
+		return 0, false
+	}
+	if best.Outcome == b.Match.AwayTeam {
+		return -*best.Point, true
+	}
+	return *best.Point, true
+}
+
+// Lines groups the best prices by line, lowest point first.
+func (b Board) Lines() []Line {
+	var lines []Line
+	index := make(map[float64]int)
+	for _, best := range b.Best {
+		point, ok := b.linePoint(best)
+		i, seen := index[point]
+		if !seen {
+			i = len(lines)
+			index[point] = i
+			line := Line{}
+			if ok {
+				line.Point = &point
+			}
+			lines = append(lines, line)
+		}
+		lines[i].Best = append(lines[i].Best, best)
+	}
+	for _, bookmaker := range b.Match.Bookmakers {
+		priced := bookmakerLines(b, bookmaker, b.Market)
+		for i := range lines {
+			var point float64
+			if lines[i].Point != nil {
+				point = *lines[i].Point
+			}
+			outcomes := priced[point]
+			if len(outcomes) != len(lines[i].Best) {
+				continue
+			}
+			total := 0.0
+			for _, o := range outcomes {
+				total += 1 / o.Price
+			}
+			lines[i].Margins = append(lines[i].Margins, total-1)
+		}
+	}
+	for i := range lines {
+		sort.Float64s(lines[i].Margins)
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Point != nil && lines[j].Point != nil && *lines[i].Point < *lines[j].Point
+	})
+	return lines
+}
+
+// NewBoard finds the best price for every outcome of market in match.
+func NewBoard(match the_odds.Match, market string) Board {
+	board := Board{Match: match, Market: market}
+	index := make(map[string]int)
+	for _, bookmaker := range match.Bookmakers {
+		for _, m := range bookmaker.Markets {
+			if m.Key != market {
+				continue
+			}
+			board.Bookmakers++
+			for _, outcome := range m.Outcomes {
+				if outcome.Price <= 1 {
+					continue
+				}
+				key := Best{Outcome: outcome.Name, Point: outcome.Point}.Label()
+				i, ok := index[key]
+				if !ok {
+					i = len(board.Best)
+					index[key] = i
+					board.Best = append(board.Best, Best{Outcome: outcome.Name, Point: outcome.Point})
+				}
+				best := &board.Best[i]
+				switch {
+				case outcome.Price > best.Price:
+					best.Price, best.Bookmakers = outcome.Price, []string{bookmaker.Title}
+				case outcome.Price == best.Price:
+					best.Bookmakers = append(best.Bookmakers, bookmaker.Title)
+				}
+			}
+		}
+	}
+	rank := func(name string) int {
+		switch name {
+		case match.HomeTeam:
+			return 0
+		case "Draw":
+			return 1
+		case match.AwayTeam:
+			return 2
+		case "Over":
+			return 3
+		case "Under":
+			return 4
+		}
+		return 5
+	}
+	sort.SliceStable(board.Best, func(i, j int) bool {
+		a, b := board.Best[i], board.Best[j]
+		if ra, rb := rank(a.Outcome), rank(b.Outcome); ra != rb {
+			return ra < rb
+		}
+		return a.Price < b.Price
+	})
+	return board
+}
+
+// NewBoards builds a Board for every match that has odds on market, soonest first.
+func NewBoards(matches []the_odds.Match, market string) []Board {
+	var boards []Board
+	for _, match := range matches {
+		if board := NewBoard(match, market); len(board.Best) > 0 {
+			boards = append(boards, board)
+		}
+	}
+	sort.SliceStable(boards, func(i, j int) bool {
+		return boards[i].Kickoff().Before(boards[j].Kickoff())
+	})
+	return boards
+}
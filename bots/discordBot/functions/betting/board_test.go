@@ -0,0 +1,64 @@
+package MatchOdds
+
+import (
+	"testing"
+
+	the_odds "discordBot/functions/betting/api"
+)
+
+func point(p float64) *float64 { return &p }
+
+func TestLineMargins(t *testing.T) {
+	totals := func(title string, outcomes ...the_odds.Outcome) the_odds.Bookmaker {
+		return the_odds.Bookmaker{Title: title, Markets: []the_odds.Market{{Key: "totals", Outcomes: outcomes}}}
+	}
+	over := func(p, price float64) the_odds.Outcome {
+		return the_odds.Outcome{Name: "Over", Point: point(p), Price: price}
+	}
+	under := func(p, price float64) the_odds.Outcome {
+		return the_odds.Outcome{Name: "Under", Point: point(p), Price: price}
+	}
+	match := the_odds.Match{HomeTeam: "Arsenal", AwayTeam: "Spurs", Bookmakers: []the_odds.Bookmaker{
+		totals("A", over(2.5, 2.0), under(2.5, 1.8), over(3.5, 3.0), under(3.5, 1.4)),
+		totals("B", over(2.5, 1.9), under(2.5, 1.9), over(3.5, 3.2)),
+		totals("C", over(2.5, 1.95), under(2.5, 1.85)),
+	}}
+	lines := NewBoard(match, "totals").Lines()
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	margin := func(prices ...float64) float64 {
+		total := 0.0
+		for _, p := range prices {
+			total += 1 / p
+		}
+		return total - 1
+	}
+
+	// Every bookmaker prices 2.5, so each has its own margin there.
+	want := []float64{margin(1.9, 1.9), margin(1.95, 1.85), margin(2.0, 1.8)}
+	got := lines[0].Margins
+	if len(got) != len(want) || !near(got[0], want[0]) || !near(got[1], want[1]) || !near(got[2], want[2]) {
+		t.Errorf("2.5 margins = %v, want %v", got, want)
+	}
+	if median, ok := lines[0].MedianMargin(); !ok || !near(median, want[1]) {
+		t.Errorf("2.5 median margin = %v, %v, want %v", median, ok, want[1])
+	}
+	if got, want := lines[0].Overround(), margin(2.0, 1.9); !near(got, want) {
+		t.Errorf("2.5 overround at best prices = %v, want %v", got, want)
+	}
+	if got, want := margins(lines[0]), "Bookmaker margin 5.3% median, 5.3% lowest. Overround at best prices 2.6%"; got != want {
+		t.Errorf("margins(2.5) = %q, want %q", got, want)
+	}
+
+	// B only prices one side of 3.5, so only A's margin counts.
+	if got := lines[1].Margins; len(got) != 1 || !near(got[0], margin(3.0, 1.4)) {
+		t.Errorf("3.5 margins = %v, want only A's %v", got, margin(3.0, 1.4))
+	}
+	if median, ok := (Line{}).MedianMargin(); ok {
+		t.Errorf("median of no margins = %v, want none", median)
+	}
+	if got, want := margins(Line{Best: []Best{{Price: 2.1}, {Price: 2.1}}}), "Overround at best prices -4.8%"; got != want {
+		t.Errorf("margins without bookmakers = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,316 @@
+package MatchOdds
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"discordBot/bot/router"
+	the_odds "discordBot/functions/betting/api"
+	"discordBot/util"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultSport, DefaultRegion and DefaultMarket are what /odds uses when
+// neither the command nor the server picks one.
+const (
+	DefaultSport  = "soccer_epl"
+	DefaultRegion = "uk"
+	DefaultMarket = "h2h"
+)
+
+// Regions lists the bookmaker regions The Odds API offers.
+var Regions = []string{"uk", "eu", "us", "us2", "au"}
+
+// Markets lists the betting markets /odds can show: match winner, handicap,
+// over/under and futures such as a league winner.
+var Markets = []string{"h2h", "spreads", "totals", "outrights"}
+
+// Defaults is a server's preferred sport, region and market for /odds.
+// Empty fields mean no preference.
+type Defaults struct {
+	Sport  string
+	Region string
+	Market string
+}
+
+// Odds is the betting module: The Odds API client, the sports list, the odds
+// snapshots it records, the prediction league and the jobs that alert servers
+// on big price moves and settle predictions.
+type Odds struct {
+	cfg       Config
+	api       *the_odds.Client
+	sports    *sportList
+	snapshots *Snapshots
+	alerts    *Alerts
+	league    *League
+	sent      sentAlerts
+	// guildDefaults returns a server's default sport, region and market.
+	guildDefaults func(guildID string) Defaults
+}
+
+// New opens the betting module's stores. guildDefaults looks up a server's
+// /odds defaults; it may be nil.
+func New(cfg Config, guildDefaults func(guildID string) Defaults) (*Odds, error) {
+	snapshots, err := OpenSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := OpenAlerts()
+	if err != nil {
+		return nil, err
+	}
+	league, err := OpenLeague()
+	if err != nil {
+		return nil, err
+	}
+	cfg = cfg.withDefaults()
+	api := the_odds.NewClient(the_odds.Options{
+		BaseURL:  cfg.APIURL,
+		CacheTTL: time.Duration(cfg.CacheTTL),
+		Reserve:  cfg.QuotaReserve,
+	})
+	return &Odds{
+		cfg:           cfg,
+		api:           api,
+		sports:        &sportList{api: api},
+		snapshots:     snapshots,
+		alerts:        alerts,
+		league:        league,
+		guildDefaults: guildDefaults,
+	}, nil
+}
+
+// Register adds the betting commands to r. manage is the permission needed to
+// change a server's odds alerts.
+func (o *Odds) Register(r *router.Router, manage router.Permission) {
+	oddsArgs := []router.Arg{
+		{Name: "sport_key", Description: "The Odds API sport key, e.g. soccer_epl", Autocomplete: o.suggestSports},
+		{Name: "region", Description: "Bookmaker region", Choices: Regions},
+		{Name: "market", Description: "Betting market", Choices: Markets},
+	}
+	r.Register(router.Command{
+		Name:        "odds",
+		Module:      "betting",
+		Args:        oddsArgs,
+		Usage:       "Usage: /odds [sport_key] [region] [market], e.g. /odds basketball_nba us totals",
+		Description: "Shows upcoming matches and odds for any sport The Odds API covers.",
+		Handler:     o.odds,
+		Subcommands: []*router.Command{
+			{
+				Name:        "show",
+				Description: "Show upcoming matches and odds for a sport.",
+				Args:        oddsArgs,
+				Handler:     o.odds,
+			},
+			{
+				Name:        "sports",
+				Description: "List the sports in season and their keys.",
+				Args:        []router.Arg{{Name: "group", Description: "Only this group, e.g. Soccer or Basketball"}},
+				Handler:     o.listSports,
+			},
+			{
+				Name:        "quota",
+				Description: "Show how many Odds API requests are left this month.",
+				Handler:     o.quota,
+			},
+			{
+				Name:        "arb",
+				Description: "Find lines where backing every outcome at its best price guarantees a profit.",
+				Args:        oddsArgs,
+				Usage:       "Usage: /odds arb [sport_key] [region] [market]",
+				Handler:     o.arb,
+			},
+			{
+				Name:        "value",
+				Description: "Find bookmaker prices that beat the no-vig consensus.",
+				Args: append(slices.Clone(oddsArgs),
+					router.Arg{Name: "threshold", Description: "Percentage edge to list, e.g. 5"}),
+				Usage:   "Usage: /odds value [sport_key] [region] [market] [threshold]",
+				Handler: o.value,
+			},
+			{
+				Name:        "movement",
+				Description: "Chart how a match's best prices have moved.",
+				Args:        []router.Arg{{Name: "match", Description: "Team names, e.g. arsenal spurs", Required: true, Rest: true}},
+				Handler:     o.movement,
+			},
+			{
+				Name:        "alerts",
+				Description: "Post in a channel when a best price moves sharply.",
+				Permission:  manage,
+				Args: []router.Arg{
+					{Name: "action", Description: "on or off", Required: true, Choices: []string{"on", "off"}},
+					{Name: "channel", Description: "Channel to post in, this one by default", Type: router.ArgChannel},
+					{Name: "sport_key", Description: "The Odds API sport key, e.g. soccer_epl", Autocomplete: o.suggestSports},
+					{Name: "threshold", Description: "Percentage move to alert on, e.g. 10"},
+				},
+				Usage:   "Usage: /odds alerts on|off [#channel] [sport_key] [threshold]",
+				Handler: o.setAlerts,
+			},
+		},
+	})
+	predictArgs := []router.Arg{
+		{Name: "match", Description: "Team names, e.g. \"arsenal spurs\"", Required: true},
+		{Name: "outcome", Description: "A team, draw, home or away", Required: true},
+		{Name: "stake", Description: fmt.Sprintf("Points to stake, %d by default", DefaultStake), Type: router.ArgInteger, Min: 1},
+		{Name: "sport_key", Description: "The Odds API sport key, the server's /odds default otherwise", Autocomplete: o.suggestSports},
+	}
+	r.Register(router.Command{
+		Name:        "predict",
+		Module:      "betting",
+		Args:        predictArgs,
+		Usage:       "Usage: /predict \"<match>\" <outcome> [stake] [sport_key], e.g. /predict \"arsenal spurs\" draw 50",
+		Description: "Stake league points on a match result at the best odds.",
+		Handler:     o.predict,
+		Subcommands: []*router.Command{
+			{
+				Name:        "place",
+				Description: "Stake league points on a match result at the best odds.",
+				Args:        predictArgs,
+				Usage:       "Usage: /predict place \"<match>\" <outcome> [stake] [sport_key]",
+				Handler:     o.predict,
+			},
+			{
+				Name:        "list",
+				Description: "Show your open predictions and points.",
+				Handler:     o.listPredictions,
+			},
+		},
+	})
+	r.Register(router.Command{
+		Name:        "leaderboard",
+		Module:      "betting",
+		Args:        []router.Arg{{Name: "season", Description: "Month of the season, e.g. 2026-10"}},
+		Description: "Ranks this server's prediction league for a season.",
+		Handler:     o.leaderboard,
+	})
+	r.Register(router.Command{
+		Name:        "football",
+		Module:      "betting",
+		Description: "Shows upcoming Premier League matches with the best odds on each outcome.",
+		Handler:     o.football,
+	})
+}
+
+// defaults fills in whatever the command left out from the server's
+// defaults, then the bot-wide ones.
+func (o *Odds) defaults(req *router.Request, d Defaults) Defaults {
+	var guild Defaults
+	if req.GuildID != "" && o.guildDefaults != nil {
+		guild = o.guildDefaults(req.GuildID)
+	}
+	pick := func(given, server, fallback string) string {
+		switch {
+		case given != "":
+			return strings.ToLower(given)
+		case server != "":
+			return server
+		}
+		return fallback
+	}
+	return Defaults{
+		Sport:  pick(d.Sport, guild.Sport, DefaultSport),
+		Region: pick(d.Region, guild.Region, DefaultRegion),
+		Market: pick(d.Market, guild.Market, DefaultMarket),
+	}
+}
+
+// ValidateDefaults checks a region and market are ones /odds accepts. Empty
+// values are allowed.
+func ValidateDefaults(d Defaults) error {
+	if d.Region != "" && !slices.Contains(Regions, d.Region) {
+		return fmt.Errorf("unknown region %q, use one of %s", d.Region, strings.Join(Regions, ", "))
+	}
+	if d.Market != "" && !slices.Contains(Markets, d.Market) {
+		return fmt.Errorf("unknown market %q, use one of %s", d.Market, strings.Join(Markets, ", "))
+	}
+	return nil
+}
+
+func (o *Odds) odds(req *router.Request) {
+	o.show(req, o.defaults(req, Defaults{Sport: req.Arg(0), Region: req.Arg(1), Market: req.Arg(2)}))
+}
+
+// football is /odds for the Premier League, with the server's region and market.
+func (o *Odds) football(req *router.Request) {
+	o.show(req, o.defaults(req, Defaults{Sport: DefaultSport}))
+}
+
+// fetch gets d's upcoming matches with odds, replying with the reason and
+// returning false if it can't.
+func (o *Odds) fetch(req *router.Request, d Defaults) ([]the_odds.Match, bool) {
+	if err := ValidateDefaults(d); err != nil {
+		req.Reply(err.Error())
+		return nil, false
+	}
+	if err := o.sports.check(req.Context(), d.Sport, d.Market); err != nil {
+		req.Reply(err.Error())
+		return nil, false
+	}
+	matches, err := o.api.GetOdds(req.Context(), d.Sport, d.Region, d.Market)
+	if err != nil {
+		req.Reply("Failed to retrieve upcoming matches: " + err.Error())
+		return nil, false
+	}
+	return matches, true
+}
+
+func (o *Odds) show(req *router.Request, d Defaults) {
+	matches, ok := o.fetch(req, d)
+	if !ok {
+		return
+	}
+	boards := NewBoards(matches, d.Market)
+	feed := Feed{Sport: d.Sport, Region: d.Region, Market: d.Market}
+	if _, err := o.snapshots.Record(feed, boards, time.Now()); err != nil {
+		util.LoggerInit("BETTING", "show").Error("Failed to save odds snapshots", "error", err)
+	}
+	MatchOdds(req, d, boards)
+}
+
+func (o *Odds) listSports(req *router.Request) {
+	sports, err := o.sports.get(req.Context())
+	if err != nil {
+		req.Reply("Failed to fetch the list of sports: " + err.Error())
+		return
+	}
+	group := strings.ToLower(req.Arg(0))
+	var lines []string
+	for _, s := range sports {
+		if !s.Active || group != "" && strings.ToLower(s.Group) != group {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("`%s` %s (%s)", s.Key, s.Title, s.Group))
+	}
+	if len(lines) == 0 {
+		req.Reply("No sports in season match that group.")
+		return
+	}
+	req.ReplyPages(router.Paginate("Sports in Season", lines, 20))
+}
+
+func (o *Odds) quota(req *router.Request) {
+	q := o.api.Quota()
+	caching := fmt.Sprintf("Odds are reused for %s per sport, region and market.", shortDuration(time.Duration(o.cfg.CacheTTL)))
+	if !q.Known {
+		req.Reply("No Odds API requests have been made since the bot started, so the quota isn't known yet. " + caching)
+		return
+	}
+	req.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       "Odds API Quota",
+		Description: caching,
+		Color:       0x00ffcc,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Remaining", Value: strconv.Itoa(q.Remaining), Inline: true},
+			{Name: "Used", Value: strconv.Itoa(q.Used), Inline: true},
+			{Name: "Last Request Cost", Value: strconv.Itoa(q.LastCost), Inline: true},
+			{Name: "Reserve", Value: fmt.Sprintf("%d, odds aren't fetched below this", o.api.Reserve())},
+		},
+		Footer:    &discordgo.MessageEmbedFooter{Text: "As of the last request"},
+		Timestamp: q.Updated.Format(time.RFC3339),
+	})
+}
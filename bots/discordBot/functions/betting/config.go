@@ -0,0 +1,94 @@
+package MatchOdds
+
+import (
+	"time"
+
+	"discordBot/bot/ratelimit"
+)
+
+// Feed is one sport's odds from one region's bookmakers on one market.
+type Feed struct {
+	Sport  string `json:"sport"`
+	Region string `json:"region,omitempty"`
+	Market string `json:"market,omitempty"`
+}
+
+// withDefaults fills in a left out region or market.
+func (f Feed) withDefaults() Feed {
+	if f.Region == "" {
+		f.Region = DefaultRegion
+	}
+	if f.Market == "" {
+		f.Market = DefaultMarket
+	}
+	return f
+}
+
+// Config is the "odds" section of the bot config.
+type Config struct {
+	// Tracked feeds are snapshotted every SnapshotEvery, along with the sports
+	// servers have /odds alerts on for. Each snapshot costs API quota.
+	Tracked       []Feed             `json:"tracked,omitempty"`
+	SnapshotEvery ratelimit.Duration `json:"snapshot_every"`
+	// MoveThreshold is the percentage a best price has to move by within
+	// MoveWindow to be alerted on, unless a server sets its own.
+	MoveThreshold float64            `json:"move_threshold"`
+	MoveWindow    ratelimit.Duration `json:"move_window"`
+	// ValueThreshold is the percentage a price has to beat the no-vig
+	// consensus by for /odds value to list it.
+	ValueThreshold float64 `json:"value_threshold"`
+	// SettleEvery is how often predictions past kick-off are settled from
+	// the scores. Each sport with such predictions costs two requests.
+	SettleEvery ratelimit.Duration `json:"settle_every"`
+	// CacheTTL is how long an API response is reused for the same sport,
+	// region and market.
+	CacheTTL ratelimit.Duration `json:"cache_ttl"`
+	// QuotaReserve is how many of the month's API requests are kept back:
+	// odds aren't fetched once fewer than this many are left.
+	QuotaReserve int `json:"quota_reserve"`
+	// APIURL replaces The Odds API's address, e.g. for a local mock.
+	APIURL string `json:"api_url,omitempty"`
+}
+
+// DefaultConfig snapshots every 30 minutes, alerts on best prices that
+// move 10% within three hours, lists value bets 5% over the consensus,
+// settles predictions hourly, reuses responses for five minutes and keeps
+// 25 requests in reserve.
+func DefaultConfig() Config {
+	return Config{
+		SnapshotEvery:  ratelimit.Duration(30 * time.Minute),
+		MoveThreshold:  10,
+		MoveWindow:     ratelimit.Duration(3 * time.Hour),
+		ValueThreshold: 5,
+		SettleEvery:    ratelimit.Duration(time.Hour),
+		CacheTTL:       ratelimit.Duration(5 * time.Minute),
+		QuotaReserve:   25,
+	}
+}
+
+// withDefaults fills in every setting left out of the config file.
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.SnapshotEvery == 0 {
+		c.SnapshotEvery = d.SnapshotEvery
+	}
+	if c.MoveThreshold == 0 {
+		c.MoveThreshold = d.MoveThreshold
+	}
+	if c.MoveWindow == 0 {
+		c.MoveWindow = d.MoveWindow
+	}
+	if c.ValueThreshold == 0 {
+		c.ValueThreshold = d.ValueThreshold
+	}
+	if c.SettleEvery == 0 {
+		c.SettleEvery = d.SettleEvery
+	}
+	if c.CacheTTL == 0 {
+		c.CacheTTL = d.CacheTTL
+	}
+	if c.QuotaReserve == 0 {
+		c.QuotaReserve = d.QuotaReserve
+	}
+	return c
+}
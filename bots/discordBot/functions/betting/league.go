@@ -0,0 +1,292 @@
+package MatchOdds
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	the_odds "discordBot/functions/betting/api"
+	"discordBot/store"
+)
+
+const (
+	leagueFile = "league.json"
+	// StartingPoints is every player's balance at the start of a season.
+	StartingPoints = 1000
+	// DefaultStake is what /predict stakes when no stake is given.
+	DefaultStake = 100
+	// voidAfter is how long after kick-off a prediction whose match never
+	// shows up completed in the scores is voided and its stake returned.
+	voidAfter = 3 * 24 * time.Hour
+)
+
+// Results a prediction settles with.
+const (
+	ResultWon  = "won"
+	ResultLost = "lost"
+	ResultVoid = "void"
+)
+
+// Prediction is one player's points staked on one outcome of a match.
+type Prediction struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"user_id"`
+	ChannelID string    `json:"channel_id"`
+	Season    string    `json:"season"`
+	Sport     string    `json:"sport"`
+	MatchID   string    `json:"match_id"`
+	HomeTeam  string    `json:"home_team"`
+	AwayTeam  string    `json:"away_team"`
+	Kickoff   time.Time `json:"kickoff"`
+	Outcome   string    `json:"outcome"`
+	Price     float64   `json:"price"`
+	Stake     int       `json:"stake"`
+	Placed    time.Time `json:"placed"`
+	// Result is empty until the prediction is settled.
+	Result string `json:"result,omitempty"`
+	// Payout is what was paid back on settlement: the winnings, the stake
+	// for a void, nothing for a loss.
+	Payout int `json:"payout,omitempty"`
+}
+
+// Title is "Home vs Away".
+func (p Prediction) Title() string {
+	return p.HomeTeam + " vs " + p.AwayTeam
+}
+
+// Returns is what the prediction pays if it wins.
+func (p Prediction) Returns() int {
+	return int(math.Round(float64(p.Stake) * p.Price))
+}
+
+// guildLeague is one server's predictions and balances.
+type guildLeague struct {
+	NextID      int          `json:"next_id"`
+	Predictions []Prediction `json:"predictions"`
+	// Balances is each player's points by season, then user ID.
+	Balances map[string]map[string]int `json:"balances"`
+}
+
+func (g *guildLeague) balance(season, userID string) int {
+	if points, ok := g.Balances[season][userID]; ok {
+		return points
+	}
+	return StartingPoints
+}
+
+func (g *guildLeague) credit(season, userID string, points int) {
+	if g.Balances == nil {
+		g.Balances = make(map[string]map[string]int)
+	}
+	if g.Balances[season] == nil {
+		g.Balances[season] = make(map[string]int)
+	}
+	g.Balances[season][userID] = g.balance(season, userID) + points
+}
+
+// Standing is one player's place in a season.
+type Standing struct {
+	UserID            string
+	Points            int
+	Predictions, Wins int
+}
+
+// League stores each server's prediction league by guild ID.
+type League struct {
+	file *store.File[map[string]*guildLeague]
+}
+
+// OpenLeague loads league.json from the data directory.
+func OpenLeague() (*League, error) {
+	file, err := store.Open(leagueFile, make(map[string]*guildLeague))
+	if err != nil {
+		return nil, err
+	}
+	return &League{file: file}, nil
+}
+
+// Season is the season a time falls in. Seasons run for a calendar month, UTC.
+func Season(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// Place stakes p.Stake of the player's points on p, taking them from their
+// balance for p.Season. It returns the prediction as saved and the balance
+// left.
+func (l *League) Place(guildID string, p Prediction) (Prediction, int, error) {
+	var balance int
+	err := l.file.Update(func(all *map[string]*guildLeague) error {
+		g := (*all)[guildID]
+		if g == nil {
+			g = &guildLeague{}
+			(*all)[guildID] = g
+		}
+		for _, other := range g.Predictions {
+			if other.UserID == p.UserID && other.MatchID == p.MatchID && other.Result == "" {
+				return fmt.Errorf("you've already predicted %s on %s", other.Outcome, other.Title())
+			}
+		}
+		if have := g.balance(p.Season, p.UserID); have < p.Stake {
+			return fmt.Errorf("you only have %d points left this season", have)
+		}
+		g.NextID++
+		p.ID = g.NextID
+		g.credit(p.Season, p.UserID, -p.Stake)
+		g.Predictions = append(g.Predictions, p)
+		balance = g.balance(p.Season, p.UserID)
+		return nil
+	})
+	return p, balance, err
+}
+
+// Open lists userID's unsettled predictions in guildID, soonest kick-off first.
+func (l *League) Open(guildID, userID string) []Prediction {
+	var out []Prediction
+	l.file.View(func(all *map[string]*guildLeague) {
+		if g := (*all)[guildID]; g != nil {
+			for _, p := range g.Predictions {
+				if p.UserID == userID && p.Result == "" {
+					out = append(out, p)
+				}
+			}
+		}
+	})
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Kickoff.Before(out[j].Kickoff)
+	})
+	return out
+}
+
+// Balance is userID's points in guildID for season.
+func (l *League) Balance(guildID, season, userID string) int {
+	balance := StartingPoints
+	l.file.View(func(all *map[string]*guildLeague) {
+		if g := (*all)[guildID]; g != nil {
+			balance = g.balance(season, userID)
+		}
+	})
+	return balance
+}
+
+// Pending lists the sports that have unsettled predictions past kick-off.
+func (l *League) Pending(now time.Time) []string {
+	seen := make(map[string]bool)
+	var sports []string
+	l.file.View(func(all *map[string]*guildLeague) {
+		for _, g := range *all {
+			for _, p := range g.Predictions {
+				if p.Result == "" && p.Kickoff.Before(now) && !seen[p.Sport] {
+					seen[p.Sport] = true
+					sports = append(sports, p.Sport)
+				}
+			}
+		}
+	})
+	sort.Strings(sports)
+	return sports
+}
+
+// Settle settles every open prediction on sport whose match has a final
+// score in scores, and voids those still unsettled voidAfter kick-off. A
+// prediction is only ever settled once, so settling the same scores again
+// changes nothing. It returns the predictions settled by guild ID.
+func (l *League) Settle(sport string, scores []the_odds.Score, now time.Time) (map[string][]Prediction, error) {
+	results := make(map[string]string)
+	for _, s := range scores {
+		if result, ok := finalResult(s); ok {
+			results[s.ID] = result
+		}
+	}
+	settled := make(map[string][]Prediction)
+	err := l.file.Update(func(all *map[string]*guildLeague) error {
+		for guildID, g := range *all {
+			for i := range g.Predictions {
+				p := &g.Predictions[i]
+				if p.Result != "" || p.Sport != sport {
+					continue
+				}
+				winner, ok := results[p.MatchID]
+				switch {
+				case ok && winner == p.Outcome:
+					p.Result, p.Payout = ResultWon, p.Returns()
+				case ok:
+					p.Result = ResultLost
+				case now.Sub(p.Kickoff) > voidAfter:
+					p.Result, p.Payout = ResultVoid, p.Stake
+				default:
+					continue
+				}
+				g.credit(p.Season, p.UserID, p.Payout)
+				settled[guildID] = append(settled[guildID], *p)
+			}
+		}
+		return nil
+	})
+	return settled, err
+}
+
+// finalResult is the winning outcome of a completed match: a team or "Draw".
+// ok is false if the match isn't over or its score can't be read.
+func finalResult(s the_odds.Score) (string, bool) {
+	if !s.Completed {
+		return "", false
+	}
+	goals := make(map[string]int)
+	for _, team := range s.Scores {
+		n, err := strconv.Atoi(team.Score)
+		if err != nil {
+			return "", false
+		}
+		goals[team.Name] = n
+	}
+	home, okHome := goals[s.HomeTeam]
+	away, okAway := goals[s.AwayTeam]
+	switch {
+	case !okHome || !okAway:
+		return "", false
+	case home > away:
+		return s.HomeTeam, true
+	case away > home:
+		return s.AwayTeam, true
+	}
+	return "Draw", true
+}
+
+// Standings ranks everyone who predicted in guildID during season, most
+// points first.
+func (l *League) Standings(guildID, season string) []Standing {
+	byUser := make(map[string]*Standing)
+	l.file.View(func(all *map[string]*guildLeague) {
+		g := (*all)[guildID]
+		if g == nil {
+			return
+		}
+		for _, p := range g.Predictions {
+			if p.Season != season {
+				continue
+			}
+			s, ok := byUser[p.UserID]
+			if !ok {
+				s = &Standing{UserID: p.UserID, Points: g.balance(season, p.UserID)}
+				byUser[p.UserID] = s
+			}
+			s.Predictions++
+			if p.Result == ResultWon {
+				s.Wins++
+			}
+		}
+	})
+	out := make([]Standing, 0, len(byUser))
+	for _, s := range byUser {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Points != out[j].Points {
+			return out[i].Points > out[j].Points
+		}
+		return out[i].UserID < out[j].UserID
+	})
+	return out
+}
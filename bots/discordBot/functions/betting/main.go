@@ -1,34 +1,133 @@
 package MatchOdds
 
 import (
-	the_odds "discordBot/functions/betting/api"
+	"discordBot/bot/router"
 	"fmt"
-	"log/slog"
-	"os"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 )
 
-func MatchOdds(server *discordgo.Session, message *discordgo.MessageCreate) error {
-	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	slog.SetDefault(logger)
+const (
+	// maxBookmakersShown is how many bookmakers are named under a best price
+	// before the rest are summed up as "+N more".
+	maxBookmakersShown = 3
+	// maxEmbedFields is Discord's limit on fields in an embed.
+	maxEmbedFields = 25
+	// maxOutrightsShown is how many outrights are listed, shortest first.
+	maxOutrightsShown = 20
+)
 
-	response, err := the_odds.GetTheOddsAPI()
-	if err != nil {
-		server.ChannelMessageSend(message.ChannelID, "Failed. Try again later.")
-		return err
+// MatchOdds replies with d's upcoming matches, one page per match, showing
+// the best price on each outcome across the region's bookmakers.
+func MatchOdds(req *router.Request, d Defaults, boards []Board) {
+	if len(boards) == 0 && d.Market == "outrights" {
+		req.Reply("No " + d.Sport + " outrights from " + d.Region + " bookmakers. Outrights have their own sport keys, e.g. soccer_epl_winner.")
+		return
+	}
+	if len(boards) == 0 {
+		req.Reply("No upcoming " + d.Sport + " matches with " + d.Market + " odds from " + d.Region + " bookmakers.")
+		return
+	}
+	pages := make([]*discordgo.MessageEmbed, len(boards))
+	for i, board := range boards {
+		pages[i] = boardEmbed(board)
 	}
+	req.ReplyPages(pages)
+}
 
-	logger.Info("API Response Received")
+// boardEmbed renders one match: kick-off, then each outcome's best price,
+// implied probability and who offers it, grouped by line.
+func boardEmbed(board Board) *discordgo.MessageEmbed {
+	description := board.Match.SportTitle
+	if board.Outright() {
+		description = "Outright"
+	}
+	if kickoff := board.Kickoff(); !kickoff.IsZero() {
+		description = fmt.Sprintf("%s, <t:%d:f> (<t:%d:R>)", description, kickoff.Unix(), kickoff.Unix())
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       board.Title(),
+		Description: description,
+		Color:       0x00ffcc,
+	}
+	lines := board.Lines()
+	switch {
+	case board.Outright():
+		embed.Description += "\n\n" + outrightList(lines[0])
+	case len(lines) == 1 && lines[0].Point == nil:
+		for _, best := range lines[0].Best {
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   best.Outcome,
+				Value:  fmt.Sprintf("**%.2f** (%.1f%%)\n%s", best.Price, best.Implied()*100, bookmakerList(best.Bookmakers)),
+				Inline: true,
+			})
+		}
+	default:
+		for i, line := range lines {
+			if i == maxEmbedFields {
+				break
+			}
+			var rows []string
+			for _, best := range line.Best {
+				rows = append(rows, fmt.Sprintf("%s **%.2f** (%.1f%%) %s", best.Label(), best.Price, best.Implied()*100, bookmakerList(best.Bookmakers)))
+			}
+			rows = append(rows, margins(line))
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:  lineName(board, line),
+				Value: strings.Join(rows, "\n"),
+			})
+		}
+	}
+	footer := fmt.Sprintf("%d lines across %d bookmakers", len(lines), board.Bookmakers)
+	switch {
+	case board.Outright():
+		footer = fmt.Sprintf("%d outcomes across %d bookmakers", len(board.Best), board.Bookmakers)
+	case len(lines) == 1:
+		footer = fmt.Sprintf("%s (%d bookmakers)", margins(lines[0]), board.Bookmakers)
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+	return embed
+}
+
+// margins sums up a line's overround: the median and lowest of what the
+// bookmakers charge on their own, then what's left at the best prices.
+func margins(line Line) string {
+	atBest := fmt.Sprintf("Overround at best prices %.1f%%", line.Overround()*100)
+	median, ok := line.MedianMargin()
+	if !ok {
+		return atBest
+	}
+	return fmt.Sprintf("Bookmaker margin %.1f%% median, %.1f%% lowest. %s", median*100, line.Margins[0]*100, atBest)
+}
+
+// lineName heads a line, e.g. "Total 2.5" or "Arsenal -1.5".
+func lineName(board Board, line Line) string {
+	switch {
+	case line.Point == nil:
+		return "Odds"
+	case line.Best[0].Outcome == "Over" || line.Best[0].Outcome == "Under":
+		return fmt.Sprintf("Total %g", *line.Point)
+	}
+	return fmt.Sprintf("%s %+g", board.Match.HomeTeam, *line.Point)
+}
 
-	for _, match := range response {
-		server.ChannelMessageSend(message.ChannelID, "--------------------------------------------------")
-		server.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Match: %s vs %s\n", match.HomeTeam, match.AwayTeam))
-		server.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Time: %s\n", match.CommenceTime))
-		server.ChannelMessageSend(message.ChannelID, fmt.Sprintf("Bookmaker: %s\n", match.Bookmaker))
-		for outcome, price := range match.Odds {
-			server.ChannelMessageSend(message.ChannelID, fmt.Sprintf("  %s: %.2f\n", outcome, price))
+// outrightList lists the outrights shortest first, one per row.
+func outrightList(line Line) string {
+	var rows []string
+	for i, best := range line.Best {
+		if i == maxOutrightsShown {
+			rows = append(rows, fmt.Sprintf("+%d more", len(line.Best)-maxOutrightsShown))
+			break
 		}
+		rows = append(rows, fmt.Sprintf("%s **%.2f** (%.1f%%) %s", best.Outcome, best.Price, best.Implied()*100, bookmakerList(best.Bookmakers)))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func bookmakerList(names []string) string {
+	if len(names) <= maxBookmakersShown {
+		return strings.Join(names, ", ")
 	}
-	return nil
+	return fmt.Sprintf("%s +%d more", strings.Join(names[:maxBookmakersShown], ", "), len(names)-maxBookmakersShown)
 }
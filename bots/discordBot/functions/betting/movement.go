@@ -0,0 +1,99 @@
+package MatchOdds
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"strings"
+
+	"discordBot/bot/router"
+	"discordBot/chart"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	chartWidth  = 800
+	chartHeight = 400
+	// maxMatchesListed is how many matches an ambiguous /odds movement lists.
+	maxMatchesListed = 10
+)
+
+// lineColors are the colours of each outcome's line, in outcome order.
+var lineColors = []color.RGBA{
+	{0x00, 0x99, 0x88, 0xff},
+	{0x88, 0x88, 0x88, 0xff},
+	{0xdd, 0x66, 0x00, 0xff},
+	{0x33, 0x66, 0xcc, 0xff},
+	{0xaa, 0x33, 0x99, 0xff},
+}
+
+func (o *Odds) movement(req *router.Request) {
+	query := req.Arg(0)
+	found := o.snapshots.Find(query)
+	if len(found) == 0 {
+		req.Reply("No odds recorded for a match matching `" + query + "` yet. They're recorded whenever /odds runs and on a schedule for tracked sports.")
+		return
+	}
+	if titles := sortedTitles(found); len(titles) > 1 {
+		if len(titles) > maxMatchesListed {
+			titles = titles[:maxMatchesListed]
+		}
+		req.Reply("More than one match fits `" + query + "`:\n- " + strings.Join(titles, "\n- "))
+		return
+	}
+	series := pickSeries(found, o.defaults(req, Defaults{}))
+
+	var lines []chart.Series
+	var fields []*discordgo.MessageEmbedField
+	for i, outcome := range series.Outcomes {
+		var points []chart.Point
+		for _, snap := range series.Snapshots {
+			if price, ok := snap.Best[outcome]; ok {
+				points = append(points, chart.Point{Time: snap.Time, Value: price})
+			}
+		}
+		if len(points) == 0 {
+			continue
+		}
+		if len(fields) == maxEmbedFields {
+			break
+		}
+		lines = append(lines, chart.Series{Name: outcome, Color: lineColors[i%len(lineColors)], Points: points})
+		first, last := points[0].Value, points[len(points)-1].Value
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   outcome,
+			Value:  fmt.Sprintf("%.2f → **%.2f** (%+.1f%%)", first, last, (last-first)/first*100),
+			Inline: true,
+		})
+	}
+	embed := &discordgo.MessageEmbed{
+		Title: series.Title(),
+		Description: fmt.Sprintf("Best %s prices from %s bookmakers, %d snapshots since <t:%d:f>. Kick-off <t:%d:f>.",
+			series.Market, series.Region, len(series.Snapshots), series.Snapshots[0].Time.Unix(), series.Kickoff.Unix()),
+		Color:  0x00ffcc,
+		Fields: fields,
+	}
+	var png bytes.Buffer
+	if err := chart.Line(&png, chartWidth, chartHeight, lines); err != nil {
+		req.ReplyEmbed(embed)
+		return
+	}
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://movement.png"}
+	req.ReplyFiles(embed, &discordgo.File{Name: "movement.png", ContentType: "image/png", Reader: &png})
+}
+
+// pickSeries chooses which feed of a match to show: the server's default
+// region and market if it was recorded, otherwise the one with the most snapshots.
+func pickSeries(found map[string]Series, d Defaults) Series {
+	var best Series
+	for _, s := range found {
+		if s.Region == d.Region && s.Market == d.Market {
+			return s
+		}
+		if len(s.Snapshots) > len(best.Snapshots) {
+			best = s
+		}
+	}
+	return best
+}
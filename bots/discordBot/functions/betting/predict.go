@@ -0,0 +1,236 @@
+package MatchOdds
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"discordBot/bot/router"
+	"discordBot/util"
+)
+
+// scoresDays is how many days back settlement asks The Odds API for scores.
+// Three is the most it allows.
+const scoresDays = 3
+
+func (o *Odds) predict(req *router.Request) {
+	if req.GuildID == "" {
+		req.Reply("The prediction league is per server, so /predict only works in one.")
+		return
+	}
+	query, pick, stakeArg := req.Arg(0), req.Arg(1), req.Arg(2)
+	stake := DefaultStake
+	if stakeArg != "" {
+		n, err := strconv.Atoi(stakeArg)
+		if err != nil || n < 1 {
+			req.Reply("`" + stakeArg + "` isn't a stake. Use a whole number of points, e.g. 100.")
+			return
+		}
+		stake = n
+	}
+	d := o.defaults(req, Defaults{Sport: req.Arg(3), Market: "h2h"})
+	matches, ok := o.fetch(req, d)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	words := strings.Fields(strings.ToLower(query))
+	var found []Board
+	for _, board := range NewBoards(matches, d.Market) {
+		if board.Kickoff().After(now) && containsWords(board.Title(), words) {
+			found = append(found, board)
+		}
+	}
+	switch {
+	case len(found) == 0:
+		req.Reply("No upcoming " + d.Sport + " match fits `" + query + "`. Predictions close at kick-off.")
+		return
+	case len(found) > 1:
+		titles := make([]string, 0, maxMatchesListed)
+		for i, board := range found {
+			if i == maxMatchesListed {
+				break
+			}
+			titles = append(titles, board.Title())
+		}
+		req.Reply("More than one match fits `" + query + "`:\n- " + strings.Join(titles, "\n- "))
+		return
+	}
+	board := found[0]
+	best, picked := pickOutcome(board, pick)
+	if !picked {
+		names := make([]string, len(board.Best))
+		for i, b := range board.Best {
+			names[i] = b.Outcome
+		}
+		req.Reply("Pick one of " + strings.Join(names, ", ") + " for " + board.Title() + ".")
+		return
+	}
+	p, balance, err := o.league.Place(req.GuildID, Prediction{
+		UserID:    req.Author.ID,
+		ChannelID: req.ChannelID,
+		Season:    Season(now),
+		Sport:     d.Sport,
+		MatchID:   board.Match.ID,
+		HomeTeam:  board.Match.HomeTeam,
+		AwayTeam:  board.Match.AwayTeam,
+		Kickoff:   board.Kickoff(),
+		Outcome:   best.Outcome,
+		Price:     best.Price,
+		Stake:     stake,
+		Placed:    now,
+	})
+	if err != nil {
+		req.Reply("Failed to place prediction: " + err.Error())
+		return
+	}
+	req.Reply(fmt.Sprintf("Predicted **%s** in %s at %.2f for %d points, paying %d if it comes in. Kick-off <t:%d:R>. You have %d points left this season.",
+		p.Outcome, p.Title(), p.Price, p.Stake, p.Returns(), p.Kickoff.Unix(), balance))
+}
+
+// pickOutcome finds the outcome a player named: its name or part of it, or
+// home, draw or away.
+func pickOutcome(board Board, pick string) (Best, bool) {
+	pick = strings.ToLower(strings.TrimSpace(pick))
+	switch pick {
+	case "home", "1":
+		pick = strings.ToLower(board.Match.HomeTeam)
+	case "away", "2":
+		pick = strings.ToLower(board.Match.AwayTeam)
+	case "x":
+		pick = "draw"
+	}
+	var found []Best
+	for _, b := range board.Best {
+		name := strings.ToLower(b.Outcome)
+		if name == pick {
+			return b, true
+		}
+		if pick != "" && strings.Contains(name, pick) {
+			found = append(found, b)
+		}
+	}
+	if len(found) != 1 {
+		return Best{}, false
+	}
+	return found[0], true
+}
+
+func (o *Odds) listPredictions(req *router.Request) {
+	if req.GuildID == "" {
+		req.Reply("The prediction league is per server, so /predict only works in one.")
+		return
+	}
+	season := Season(time.Now())
+	balance := o.league.Balance(req.GuildID, season, req.Author.ID)
+	open := o.league.Open(req.GuildID, req.Author.ID)
+	if len(open) == 0 {
+		req.Reply(fmt.Sprintf("You have no open predictions and %d points this season.", balance))
+		return
+	}
+	lines := make([]string, len(open))
+	for i, p := range open {
+		lines[i] = fmt.Sprintf("**%s** in %s at %.2f, %d points to win %d, <t:%d:R>", p.Outcome, p.Title(), p.Price, p.Stake, p.Returns(), p.Kickoff.Unix())
+	}
+	req.ReplyPages(router.Paginate(fmt.Sprintf("Open Predictions (%d points left)", balance), lines, 10))
+}
+
+func (o *Odds) leaderboard(req *router.Request) {
+	if req.GuildID == "" {
+		req.Reply("The prediction league is per server, so /leaderboard only works in one.")
+		return
+	}
+	season := req.Arg(0)
+	if season == "" {
+		season = Season(time.Now())
+	}
+	if _, err := time.Parse("2006-01", season); err != nil {
+		req.Reply("`" + season + "` isn't a season. Seasons are months, e.g. " + Season(time.Now()) + ".")
+		return
+	}
+	standings := o.league.Standings(req.GuildID, season)
+	if len(standings) == 0 {
+		req.Reply("Nobody has made a prediction in " + season + " yet. Start with /predict.")
+		return
+	}
+	lines := make([]string, len(standings))
+	for i, s := range standings {
+		lines[i] = fmt.Sprintf("%d. <@%s> **%d** points, %d won of %d", i+1, s.UserID, s.Points, s.Wins, s.Predictions)
+	}
+	req.ReplyPages(router.Paginate("Prediction League "+season, lines, 20))
+}
+
+// RunSettlement settles predictions every SettleEvery from The Odds API's
+// scores, for each sport with predictions past kick-off, and tells players
+// how they did in the channel they predicted in. It returns when ctx is
+// cancelled.
+func (o *Odds) RunSettlement(ctx context.Context, session router.Session) {
+	logger := util.LoggerInit("BETTING", "Settlement")
+	every := time.Duration(o.cfg.SettleEvery)
+	if every <= 0 {
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, sport := range o.league.Pending(time.Now()) {
+			scores, err := o.api.GetScores(ctx, sport, scoresDays)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				logger.Warn("Failed to fetch scores", "sport", sport, "error", err)
+				continue
+			}
+			settled, err := o.league.Settle(sport, scores, time.Now())
+			if err != nil {
+				logger.Error("Failed to settle predictions", "sport", sport, "error", err)
+				continue
+			}
+			for _, predictions := range settled {
+				announce(session, predictions)
+			}
+		}
+	}
+}
+
+// announce posts settled predictions to the channels they were made in.
+func announce(session router.Session, predictions []Prediction) {
+	logger := util.LoggerInit("BETTING", "Settlement")
+	byChannel := make(map[string][]string)
+	var channels []string
+	for _, p := range predictions {
+		if _, ok := byChannel[p.ChannelID]; !ok {
+			channels = append(channels, p.ChannelID)
+		}
+		byChannel[p.ChannelID] = append(byChannel[p.ChannelID], settledLine(p))
+	}
+	for _, channelID := range channels {
+		text := "**Predictions settled**\n" + strings.Join(byChannel[channelID], "\n")
+		for _, msg := range router.SplitMessage(text, router.MessageLimit) {
+			if _, err := session.ChannelMessageSend(channelID, msg); err != nil {
+				logger.Warn("Could not post settled predictions", "channel", channelID, "error", err)
+				break
+			}
+		}
+	}
+}
+
+// settledLine describes a settled prediction, e.g. "✅ <@id> Arsenal in
+// Arsenal vs Spurs won 210 points".
+func settledLine(p Prediction) string {
+	switch p.Result {
+	case ResultWon:
+		return fmt.Sprintf("✅ <@%s> %s in %s won %d points", p.UserID, p.Outcome, p.Title(), p.Payout)
+	case ResultVoid:
+		return fmt.Sprintf("↩️ <@%s> %s in %s is void, %d points returned", p.UserID, p.Outcome, p.Title(), p.Payout)
+	}
+	return fmt.Sprintf("❌ <@%s> %s in %s lost %d points", p.UserID, p.Outcome, p.Title(), p.Stake)
+}
@@ -0,0 +1,40 @@
+package MatchOdds
+
+import (
+	"strings"
+	"testing"
+
+	"discordBot/bot/router"
+	"discordBot/bot/router/routertest"
+)
+
+func TestAnnounceSplitsLongSettlements(t *testing.T) {
+	var predictions []Prediction
+	for i := 0; i < 60; i++ {
+		predictions = append(predictions, Prediction{
+			UserID: "100", ChannelID: "200", HomeTeam: "Wolverhampton Wanderers", AwayTeam: "Brighton and Hove Albion",
+			Outcome: "Brighton and Hove Albion", Result: ResultWon, Payout: 210,
+		})
+	}
+	predictions = append(predictions, Prediction{UserID: "101", ChannelID: "201", HomeTeam: "Arsenal", AwayTeam: "Spurs", Outcome: "Draw", Result: ResultLost})
+
+	s := &routertest.Session{}
+	announce(s, predictions)
+
+	var lines int
+	for i, msg := range s.SentTo("200") {
+		if len(msg.Content) > router.MessageLimit {
+			t.Errorf("message %d is %d characters, over Discord's limit", i, len(msg.Content))
+		}
+		lines += strings.Count(msg.Content, "✅")
+	}
+	if got := len(s.SentTo("200")); got < 2 {
+		t.Errorf("60 settlements went out in %d message, want them split", got)
+	}
+	if lines != 60 {
+		t.Errorf("announced %d of 60 settlements", lines)
+	}
+	if got := s.SentTo("201"); len(got) != 1 || !strings.HasPrefix(got[0].Content, "**Predictions settled**\n") {
+		t.Errorf("other channel got %+v, want one message under the header", got)
+	}
+}
@@ -0,0 +1,209 @@
+package MatchOdds
+
+import (
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"discordBot/store"
+)
+
+const (
+	snapshotFile = "oddssnapshots.json"
+	// snapshotRetention is how long after kick-off a match's snapshots are kept.
+	snapshotRetention = 48 * time.Hour
+)
+
+// Snapshot is the best price on each outcome of a match at one time.
+type Snapshot struct {
+	Time time.Time          `json:"t"`
+	Best map[string]float64 `json:"best"`
+}
+
+// Series is every snapshot of one match in one feed.
+type Series struct {
+	Sport    string    `json:"sport"`
+	Region   string    `json:"region"`
+	Market   string    `json:"market"`
+	HomeTeam string    `json:"home_team"`
+	AwayTeam string    `json:"away_team"`
+	Kickoff  time.Time `json:"kickoff"`
+	// Event names an outright, which has no teams.
+	Event string `json:"event,omitempty"`
+	// Outcomes lists the outcome labels in the order /odds shows them.
+	Outcomes  []string   `json:"outcomes"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// Title is "Home vs Away", or the event for outrights.
+func (s Series) Title() string {
+	if s.Event != "" {
+		return s.Event
+	}
+	return s.HomeTeam + " vs " + s.AwayTeam
+}
+
+func seriesKey(matchID string, feed Feed) string {
+	return matchID + "/" + feed.Region + "/" + feed.Market
+}
+
+// Move is a best price that changed within the alert window.
+type Move struct {
+	Key     string
+	Series  Series
+	Outcome string
+	// From is the price Since, To the latest.
+	From, To float64
+	Since    time.Time
+}
+
+// Change is the move as a fraction of the earlier price: negative when the
+// price shortened, positive when it drifted.
+func (m Move) Change() float64 {
+	return (m.To - m.From) / m.From
+}
+
+// Snapshots is the local history of best prices, by match and feed.
+type Snapshots struct {
+	file *store.File[map[string]*Series]
+}
+
+// OpenSnapshots loads oddssnapshots.json from the data directory.
+func OpenSnapshots() (*Snapshots, error) {
+	file, err := store.Open(snapshotFile, make(map[string]*Series))
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshots{file: file}, nil
+}
+
+// Record saves the best prices on boards at time at. A match whose prices
+// haven't changed since its last snapshot isn't saved again. Matches long
+// finished are dropped. It returns the keys of every match on the boards.
+func (s *Snapshots) Record(feed Feed, boards []Board, at time.Time) ([]string, error) {
+	keys := make([]string, 0, len(boards))
+	err := s.file.Update(func(all *map[string]*Series) error {
+		for _, board := range boards {
+			key := seriesKey(board.Match.ID, feed)
+			keys = append(keys, key)
+			series, ok := (*all)[key]
+			if !ok {
+				series = &Series{
+					Sport:    feed.Sport,
+					Region:   feed.Region,
+					Market:   feed.Market,
+					HomeTeam: board.Match.HomeTeam,
+					AwayTeam: board.Match.AwayTeam,
+				}
+				if board.Outright() {
+					series.Event = board.Title()
+				}
+				(*all)[key] = series
+			}
+			series.Kickoff = board.Kickoff()
+			best := make(map[string]float64, len(board.Best))
+			for _, b := range board.Best {
+				best[b.Label()] = b.Price
+				if !slices.Contains(series.Outcomes, b.Label()) {
+					series.Outcomes = append(series.Outcomes, b.Label())
+				}
+			}
+			if n := len(series.Snapshots); n > 0 && maps.Equal(series.Snapshots[n-1].Best, best) {
+				continue
+			}
+			series.Snapshots = append(series.Snapshots, Snapshot{Time: at, Best: best})
+		}
+		cutoff := at.Add(-snapshotRetention)
+		for key, series := range *all {
+			if !series.Kickoff.IsZero() && series.Kickoff.Before(cutoff) {
+				delete(*all, key)
+			}
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// Moves finds, for each outcome of each match in keys, the biggest change
+// between its latest best price and any best price in the window before it.
+func (s *Snapshots) Moves(keys []string, window time.Duration) []Move {
+	var moves []Move
+	s.file.View(func(all *map[string]*Series) {
+		for _, key := range keys {
+			series, ok := (*all)[key]
+			if !ok || len(series.Snapshots) < 2 {
+				continue
+			}
+			latest := series.Snapshots[len(series.Snapshots)-1]
+			info := *series
+			info.Snapshots = nil
+			info.Outcomes = slices.Clone(series.Outcomes)
+			for _, outcome := range series.Outcomes {
+				to, ok := latest.Best[outcome]
+				if !ok {
+					continue
+				}
+				move := Move{Key: key, Series: info, Outcome: outcome, From: to, To: to}
+				for _, snap := range series.Snapshots[:len(series.Snapshots)-1] {
+					from, ok := snap.Best[outcome]
+					if !ok || latest.Time.Sub(snap.Time) > window {
+						continue
+					}
+					if candidate := (Move{From: from, To: to}); math.Abs(candidate.Change()) > math.Abs(move.Change()) {
+						move.From, move.Since = from, snap.Time
+					}
+				}
+				if move.From != move.To {
+					moves = append(moves, move)
+				}
+			}
+		}
+	})
+	return moves
+}
+
+// Find returns every series whose match title contains all the words of
+// query, ignoring case, soonest kick-off first.
+func (s *Snapshots) Find(query string) map[string]Series {
+	words := strings.Fields(strings.ToLower(query))
+	found := make(map[string]Series)
+	s.file.View(func(all *map[string]*Series) {
+		for key, series := range *all {
+			if containsWords(series.Title(), words) {
+				copied := *series
+				copied.Outcomes = slices.Clone(series.Outcomes)
+				copied.Snapshots = slices.Clone(series.Snapshots)
+				found[key] = copied
+			}
+		}
+	})
+	return found
+}
+
+// containsWords reports whether title contains every one of the lowercase
+// words, ignoring case. No words match nothing.
+func containsWords(title string, words []string) bool {
+	title = strings.ToLower(title)
+	for _, w := range words {
+		if !strings.Contains(title, w) {
+			return false
+		}
+	}
+	return len(words) > 0
+}
+
+// sortedTitles lists the distinct match titles in series, soonest kick-off first.
+func sortedTitles(series map[string]Series) []string {
+	kickoffs := make(map[string]time.Time)
+	for _, s := range series {
+		kickoffs[s.Title()] = s.Kickoff
+	}
+	titles := slices.Collect(maps.Keys(kickoffs))
+	sort.Slice(titles, func(i, j int) bool {
+		return kickoffs[titles[i]].Before(kickoffs[titles[j]])
+	})
+	return titles
+}
@@ -0,0 +1,100 @@
+package MatchOdds
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	the_odds "discordBot/functions/betting/api"
+)
+
+const (
+	// sportsTTL is how long the list of sports is reused before asking again.
+	sportsTTL = time.Hour
+	// suggestTimeout keeps autocomplete inside Discord's three second limit.
+	suggestTimeout = 2 * time.Second
+)
+
+// sportList caches The Odds API's list of sports, in season or not.
+type sportList struct {
+	api     *the_odds.Client
+	mu      sync.Mutex
+	sports  []the_odds.Sport
+	fetched time.Time
+}
+
+// get returns every sport, refreshing the list when it's older than sportsTTL.
+// If the refresh fails the old list is used, if there is one.
+func (l *sportList) get(ctx context.Context) ([]the_odds.Sport, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.sports != nil && time.Since(l.fetched) < sportsTTL {
+		return l.sports, nil
+	}
+	sports, err := l.api.GetSports(ctx, true)
+	if err != nil {
+		if l.sports != nil {
+			return l.sports, nil
+		}
+		return nil, err
+	}
+	l.sports, l.fetched = sports, time.Now()
+	return sports, nil
+}
+
+// check makes sure key is a sport that's in season and offers market:
+// outrights have sport keys of their own, e.g. soccer_epl_winner. When the
+// list of sports can't be fetched the key is let through and The Odds API
+// gets to decide.
+func (l *sportList) check(ctx context.Context, key, market string) error {
+	sports, err := l.get(ctx)
+	if err != nil {
+		return nil
+	}
+	for _, s := range sports {
+		if s.Key == key {
+			if !s.Active {
+				return fmt.Errorf("%s is out of season, so there are no odds for it right now", s.Title)
+			}
+			if s.HasOutrights != (market == "outrights") {
+				return outrightsError(s, market, sports)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown sport `%s`. See /odds sports for the keys", key)
+}
+
+// outrightsError explains that a futures key needs the outrights market, or
+// that a match key doesn't have it, naming a futures key to try.
+func outrightsError(sport the_odds.Sport, market string, sports []the_odds.Sport) error {
+	if sport.HasOutrights {
+		return fmt.Errorf("`%s` is a futures market, use the outrights market with it", sport.Key)
+	}
+	for _, s := range sports {
+		if s.Active && s.HasOutrights && strings.HasPrefix(s.Key, sport.Key) {
+			return fmt.Errorf("`%s` has no %s odds, outrights are under `%s`", sport.Key, market, s.Key)
+		}
+	}
+	return fmt.Errorf("`%s` has no %s odds. See /odds sports for the futures keys", sport.Key, market)
+}
+
+// suggestSports offers in-season sport keys matching partial for autocomplete.
+func (o *Odds) suggestSports(partial string) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), suggestTimeout)
+	defer cancel()
+	sports, err := o.sports.get(ctx)
+	if err != nil {
+		return nil
+	}
+	partial = strings.ToLower(partial)
+	var out []string
+	for _, s := range sports {
+		if s.Active && (strings.Contains(s.Key, partial) || strings.Contains(strings.ToLower(s.Title), partial)) {
+			out = append(out, s.Key)
+		}
+	}
+	return out
+}
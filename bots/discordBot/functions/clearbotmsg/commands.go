@@ -0,0 +1,24 @@
+package clearbotmsg
+
+import (
+	"discordBot/bot/config"
+	"discordBot/bot/router"
+)
+
+// Register adds the /clear command to r.
+func Register(r *router.Router) {
+	r.Register(router.Command{
+		Name:        "clear",
+		Module:      "utility",
+		Permission:  config.PermClearMessages,
+		Description: "Clears 100 bot sent messages.",
+		Handler:     handleClear,
+	})
+}
+
+func handleClear(req *router.Request) {
+	ok := ClearBotMessages(req.Author.ID, req.ChannelID, req.Session)
+	if !ok {
+		req.Reply("failed to clear messages!")
+	}
+}
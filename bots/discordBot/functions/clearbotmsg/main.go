@@ -1,21 +1,20 @@
 package clearbotmsg
 
 import (
+	"discordBot/bot/router"
 	util "discordBot/util"
 	"fmt"
-
-	"github.com/bwmarrin/discordgo"
 )
 
-func ClearBotMessages(userID, channelID string, server *discordgo.Session, message *discordgo.MessageCreate) bool {
+func ClearBotMessages(userID, channelID string, server router.Session) bool {
 	logger := util.LoggerInit("ClearBotMessages", "clearbotmsg")
 
 	limit := 100
 
 	for {
-		messages, err := server.ChannelMessages(message.ChannelID, limit, "", "", "")
+		messages, err := server.ChannelMessages(channelID, limit, "", "", "")
 		if err != nil {
-			server.ChannelMessageSend(message.ChannelID, fmt.Sprintf("failed to load previous messages! %v ", err))
+			server.ChannelMessageSend(channelID, fmt.Sprintf("failed to load previous messages! %v ", err))
 			return false
 		}
 
@@ -30,7 +29,7 @@ func ClearBotMessages(userID, channelID string, server *discordgo.Session, messa
 					logger.Error("TTL expired", "error", err)
 					continue
 				} else {
-					err = server.ChannelMessageDelete(message.ChannelID, msg.ID)
+					err = server.ChannelMessageDelete(channelID, msg.ID)
 					if err != nil {
 						continue
 					}
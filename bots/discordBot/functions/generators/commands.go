@@ -0,0 +1,65 @@
+package generators
+
+import (
+	"strconv"
+
+	"discordBot/bot/router"
+)
+
+// Register adds the generator commands to r.
+func Register(r *router.Router) {
+	r.Register(router.Command{
+		Name:        "number",
+		Module:      "generators",
+		Args:        []router.Arg{{Name: "length", Description: "Number of digits", Type: router.ArgInteger, Required: true, Min: 1, Max: 18}},
+		Usage:       "Please provide a valid length for the random number. Example: /number 5",
+		Description: "Generates a random number with the specified number of digits (1-18).",
+		Handler:     handleNumber,
+	})
+	r.Register(router.Command{
+		Name:        "username",
+		Module:      "generators",
+		Args:        []router.Arg{{Name: "input", Description: "Name to base the username on", Required: true}},
+		Usage:       "Please provide a valid input for the username. Example: /username JohnDoe",
+		Description: "Generates a realistic username based on your input.",
+		Handler:     handleUsername,
+	})
+	r.Register(router.Command{
+		Name:        "string",
+		Module:      "generators",
+		Args:        []router.Arg{{Name: "length", Description: "Number of characters", Type: router.ArgInteger, Required: true, Min: 1}},
+		Usage:       "Please provide a valid length for the random string. Example: /string 10",
+		Description: "Generates a random string with the specified length.",
+		Handler:     handleString,
+	})
+}
+
+func handleNumber(req *router.Request) {
+	input := req.Arg(0)
+	length, err := strconv.Atoi(input)
+	if err != nil || length <= 0 {
+		req.Reply("Please provide a valid positive integer for the length.")
+		return
+	}
+	if length > 18 {
+		req.Reply("Please provide a number length between 1 and 18.")
+		return
+	}
+	randomNumber := GenerateRandomNumber(input)
+	req.Reply("```Generated Random Number: " + randomNumber + "```")
+}
+
+func handleUsername(req *router.Request) {
+	username := GenerateUsername(req.Arg(0))
+	req.Reply("```Generated Username: " + username + "```")
+}
+
+func handleString(req *router.Request) {
+	length, err := strconv.Atoi(req.Arg(0))
+	if err != nil || length <= 0 {
+		req.Reply("Please provide a valid positive integer for the length.")
+		return
+	}
+	randomString := GenerateRandomString(length)
+	req.Reply("```Generated Random String: " + randomString + "```")
+}
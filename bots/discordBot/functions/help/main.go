@@ -1,14 +1,19 @@
 package help
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"discordBot/bot/router"
 
-func DisplayHelp(channelID string, server *discordgo.Session, message *discordgo.MessageCreate) error {
+	"github.com/bwmarrin/discordgo"
+)
+
+func DisplayHelp(req *router.Request) error {
 	embeddedMsg := &discordgo.MessageEmbed{
 		Title: "Bot Help",
 		Color: 0x00ffcc,
 		Fields: []*discordgo.MessageEmbedField{
 			{Name: "\u200B", Value: "_**General Commands:**_", Inline: false},
 			{Name: "/help", Value: "Displays this message!"},
+			{Name: "/config", Value: "Server admins: set allowed channels, prefix, modules and locale."},
 			{Name: "\u200B", Value: "_**Betting Commands:**_", Inline: false},
 			//Util help
 			{Name: "/help bets", Value: "Displays all betting commands."},
@@ -27,11 +32,10 @@ func DisplayHelp(channelID string, server *discordgo.Session, message *discordgo
 			{Name: "/help market", Value: "Displays Steam Market commands."},
 		},
 	}
-	server.ChannelMessageSendEmbed(channelID, embeddedMsg)
-	return nil
+	return req.ReplyEmbed(embeddedMsg)
 }
 
-func DisplayHelpSteam(channelID string, server *discordgo.Session, message *discordgo.MessageCreate) error {
+func DisplayHelpSteam(req *router.Request) error {
 	embeddedMsg := &discordgo.MessageEmbed{
 		Title: "Steam Bot Commands:",
 		Color: 0x00ffcc,
@@ -43,11 +47,10 @@ func DisplayHelpSteam(channelID string, server *discordgo.Session, message *disc
 			{Name: "/report <url/steam64> [amount]", Value: "Report a player using the specified number of bots (default 1)."},
 		},
 	}
-	server.ChannelMessageSendEmbed(channelID, embeddedMsg)
-	return nil
+	return req.ReplyEmbed(embeddedMsg)
 }
 
-func DisplayHelpMail(channelID string, server *discordgo.Session, message *discordgo.MessageCreate) error {
+func DisplayHelpMail(req *router.Request) error {
 	embeddedMsg := &discordgo.MessageEmbed{
 		Title: "Temp Mail Commands:",
 		Color: 0x00ffcc,
@@ -60,37 +63,45 @@ func DisplayHelpMail(channelID string, server *discordgo.Session, message *disco
 			{Name: "/address <token>", Value: "Get the GuerrillaMail email address associated with your token. \nExample: /address <INBOX_TOKEN>"},
 		},
 	}
-	server.ChannelMessageSendEmbed(channelID, embeddedMsg)
-	return nil
+	return req.ReplyEmbed(embeddedMsg)
 }
 
-func DisplayUtilityHelp(channelID string, server *discordgo.Session, message *discordgo.MessageCreate) error {
+func DisplayUtilityHelp(req *router.Request) error {
 	embeddedMsg := &discordgo.MessageEmbed{
 		Title: "Utility Commands:",
 		Color: 0x00ffcc,
 		Fields: []*discordgo.MessageEmbedField{
 			{Name: "/clear", Value: "Clears _100_ bot sent messages!"},
 			{Name: "/proxy", Value: "Sends 1, tested; working, HTTP proxy."},
+			{Name: "/servers", Value: "Checks whether the game servers are online, with players, version and latency."},
+			{Name: "/servers add|remove|list", Value: "Changes the game servers this server checks, e.g. /servers add \"Minecraft Vanilla\" mc.example.com 25565 minecraft. Adding and removing needs manage_config."},
 		},
 	}
-	server.ChannelMessageSendEmbed(channelID, embeddedMsg)
-	return nil
+	return req.ReplyEmbed(embeddedMsg)
 
 }
 
-func DisplayBettingHelp(channelID string, server *discordgo.Session, message *discordgo.MessageCreate) error {
+func DisplayBettingHelp(req *router.Request) error {
 	embeddedMsg := &discordgo.MessageEmbed{
 		Title: "Betting Commands:",
 		Color: 0x00ffcc,
 		Fields: []*discordgo.MessageEmbedField{
-			{Name: "/football", Value: "Pulls all upcoming football matches in the UK and displays odds grouped by bookies and match"},
+			{Name: "/odds [sport_key] [region] [market]", Value: "Shows upcoming matches one page each, with the best price on every outcome, its implied probability, the bookmakers' own margin (median and lowest) and the overround at the best prices, e.g. /odds basketball_nba us totals. Markets are h2h, spreads, totals and outrights; spreads and totals are grouped by line. Without arguments it uses the server's defaults from /config odds."},
+			{Name: "/odds sports [group]", Value: "Lists the sports in season and their keys."},
+			{Name: "/odds quota", Value: "Shows how many Odds API requests are left this month and how many are held in reserve."},
+			{Name: "/odds arb [sport_key] [region] [market]", Value: "Finds lines where backing every outcome at its best price guarantees a profit, with how to split a 100 stake."},
+			{Name: "/odds value [sport_key] [region] [market] [threshold]", Value: "Lists bookmaker prices that beat the no-vig consensus of all bookmakers by the threshold percentage (5 by default), with the edge."},
+			{Name: "/odds movement <match>", Value: "Charts how a match's best prices have moved since the bot started recording them, e.g. /odds movement arsenal spurs."},
+			{Name: "/odds alerts on|off [#channel] [sport_key] [threshold]", Value: "Posts in a channel when a best price moves by more than the threshold percentage. Needs manage_config."},
+			{Name: "/predict \"<match>\" <outcome> [stake] [sport_key]", Value: "Stakes league points (100 by default) on a match result at the current best odds, before kick-off, e.g. /predict \"arsenal spurs\" draw. /predict list shows your open predictions."},
+			{Name: "/leaderboard [season]", Value: "Ranks the server's prediction league. Seasons are calendar months and everyone starts each with 1000 points."},
+			{Name: "/football", Value: "Shows /odds for the Premier League."},
 		},
 	}
-	server.ChannelMessageSendEmbed(channelID, embeddedMsg)
-	return nil
+	return req.ReplyEmbed(embeddedMsg)
 }
 
-func DisplayHelpGenerators(channelID string, server *discordgo.Session, message *discordgo.MessageCreate) error {
+func DisplayHelpGenerators(req *router.Request) error {
 	embeddedMsg := &discordgo.MessageEmbed{
 		Title: "Generator Commands:",
 		Color: 0x00ffcc,
@@ -100,18 +111,21 @@ func DisplayHelpGenerators(channelID string, server *discordgo.Session, message
 			{Name: "/string <length>", Value: "Generates a random string with the specified length. Example: !string 8"},
 		},
 	}
-	server.ChannelMessageSendEmbed(channelID, embeddedMsg)
-	return nil
+	return req.ReplyEmbed(embeddedMsg)
 }
 
-func DisplayHelpMarket(channelID string, server *discordgo.Session, message *discordgo.MessageCreate) error {
+func DisplayHelpMarket(req *router.Request) error {
 	embeddedMsg := &discordgo.MessageEmbed{
 		Title: "Steam Market Commands:",
 		Color: 0x00ffcc,
 		Fields: []*discordgo.MessageEmbedField{
-			{Name: "/price <item_name | skin_name (wear)>", Value: "Fetches the price overview for the specified Steam Market item. Example: /price AK-47 | Redline (Field-Tested)"},
+			{Name: "/price <item_name> [game] [currency]", Value: "Fetches the price overview for the specified Steam Market item. Close matches work too, with wears as fn, mw, ft, ww or bs. Example: /price ak redline ft cs2 EUR"},
+			{Name: "/inventory <steamid64 | profile link> [game]", Value: "Values a public Steam inventory: total, the 10 most valuable items and anything without a market price."},
+			{Name: "/market defaults [game] [currency]", Value: "Sets the game (cs2, dota2, tf2, rust or an app ID) and currency your lookups use. /market reset goes back to the server default."},
+			{Name: "/pricehistory <item_name> [days]", Value: "Charts the recorded lowest and median price over the last 30 days, or [days]."},
+			{Name: "/portfolio add <quantity> <buy_price> <item_name>", Value: "Records a purchase. /portfolio shows each item's value after Steam's fee, your unrealized profit or loss and each purchase's #, /portfolio export sends a CSV and /portfolio remove <number> deletes a purchase."},
+			{Name: "/watch add below|above <price> <item_name>", Value: "DMs you when the lowest price crosses the target. See your watches with /watch list and stop one with /watch remove <number>."},
 		},
 	}
-	server.ChannelMessageSendEmbed(channelID, embeddedMsg)
-	return nil
+	return req.ReplyEmbed(embeddedMsg)
 }
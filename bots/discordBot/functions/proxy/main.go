@@ -5,6 +5,7 @@
 package proxy
 
 import (
+	"context"
 	"discordBot/util"
 	"encoding/json"
 )
@@ -22,7 +23,7 @@ type ProxyEntry struct {
 	Port string `json:"port"`
 }
 
-func ProxyHandler(proxyType string) []string {
+func ProxyHandler(ctx context.Context, proxyType string) []string {
 	logger := util.LoggerInit("PROXY HANDLER", "PROXY")
 
 	mode := proxyType
@@ -31,11 +32,11 @@ func ProxyHandler(proxyType string) []string {
 
 	switch mode {
 	case "http":
-		output, err = util.ExecBinary(binaryPath, "http")
+		output, err = util.ExecBinary(ctx, binaryPath, "http")
 	case "https":
-		output, err = util.ExecBinary(binaryPath, "https")
+		output, err = util.ExecBinary(ctx, binaryPath, "https")
 	case "socks5":
-		output, err = util.ExecBinary(binaryPath, "socks5")
+		output, err = util.ExecBinary(ctx, binaryPath, "socks5")
 	default:
 		logger.Error("Invalid proxy type", "type", proxyType)
 		return []string{}
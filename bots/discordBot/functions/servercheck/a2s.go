@@ -0,0 +1,117 @@
+package servercheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	a2sInfo      = 0x54
+	a2sInfoReply = 0x49
+	a2sChallenge = 0x41
+	// theShip is the one game whose A2S_INFO reply has extra fields before
+	// the version.
+	theShip = 2400
+)
+
+var a2sHeader = []byte{0xff, 0xff, 0xff, 0xff}
+
+// queryA2S asks a Source engine server, or a game using its query protocol
+// like Valheim, for A2S_INFO. Servers that want a challenge get asked again
+// with it; the latency is the round trip of the request that was answered.
+func queryA2S(ctx context.Context, dialer *net.Dialer, host string, port int) (Status, error) {
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	request := append(append([]byte{}, a2sHeader...), a2sInfo)
+	request = append(request, "Source Engine Query\x00"...)
+	base := len(request)
+	buf := make([]byte, 1400)
+	for attempt := 0; attempt < 2; attempt++ {
+		sent := time.Now()
+		if _, err := conn.Write(request); err != nil {
+			return Status{}, err
+		}
+		n, err := conn.Read(buf)
+		if err != nil {
+			return Status{}, err
+		}
+		latency := time.Since(sent)
+		reply := buf[:n]
+		if len(reply) < 5 || !bytes.Equal(reply[:4], a2sHeader) {
+			return Status{}, errors.New("not an A2S reply")
+		}
+		switch reply[4] {
+		case a2sChallenge:
+			if len(reply) < 9 {
+				return Status{}, errors.New("short A2S challenge")
+			}
+			request = append(request[:base:base], reply[5:9]...)
+		case a2sInfoReply:
+			status, err := parseA2SInfo(reply[5:])
+			status.Latency = latency
+			return status, err
+		default:
+			return Status{}, fmt.Errorf("unexpected A2S reply %#x", reply[4])
+		}
+	}
+	return Status{}, errors.New("server kept asking for a challenge")
+}
+
+// parseA2SInfo reads an A2S_INFO reply after its header byte.
+func parseA2SInfo(data []byte) (Status, error) {
+	r := bytes.NewReader(data)
+	if _, err := r.ReadByte(); err != nil {
+		return Status{}, err
+	}
+	name, _ := readCString(r)
+	readCString(r) // map
+	readCString(r) // folder
+	readCString(r) // game
+	var id uint16
+	binary.Read(r, binary.LittleEndian, &id)
+	var counts [3]byte // players, max players, bots
+	if _, err := r.Read(counts[:]); err != nil {
+		return Status{}, errors.New("short A2S_INFO reply")
+	}
+	r.Seek(4, io.SeekCurrent) // server type, environment, visibility, VAC
+	if id == theShip {
+		r.Seek(3, io.SeekCurrent) // mode, witnesses, duration
+	}
+	version, _ := readCString(r)
+	return Status{
+		Online:     true,
+		Protocol:   A2S,
+		Version:    version,
+		MOTD:       name,
+		Players:    int(counts[0]),
+		MaxPlayers: int(counts[1]),
+	}, nil
+}
+
+func readCString(r *bytes.Reader) (string, error) {
+	var b []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return string(b), err
+		}
+		if c == 0 {
+			return string(b), nil
+		}
+		b = append(b, c)
+	}
+}
@@ -0,0 +1,160 @@
+package servercheck
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"discordBot/bot/router"
+)
+
+// Checker is the server status module: each Discord server's inventory of
+// game servers and the command that probes them.
+type Checker struct {
+	cfg         Config
+	inventories *Inventories
+}
+
+// New opens the server inventories. Guilds without their own list use
+// cfg.Inventory.
+func New(cfg Config) (*Checker, error) {
+	cfg = cfg.withDefaults()
+	for _, srv := range cfg.Inventory {
+		if err := srv.Validate(); err != nil {
+			return nil, fmt.Errorf("servers.inventory: %w", err)
+		}
+	}
+	inventories, err := OpenInventories(cfg.Inventory)
+	if err != nil {
+		return nil, err
+	}
+	return &Checker{cfg: cfg, inventories: inventories}, nil
+}
+
+// Register adds the server status command to r. manage is the permission
+// needed to change a server's list.
+func (c *Checker) Register(r *router.Router, manage router.Permission) {
+	r.Register(router.Command{
+		Name:        "servers",
+		Module:      "servers",
+		Description: "Checks whether the game servers are online.",
+		Handler:     c.check,
+		Subcommands: []*router.Command{
+			{
+				Name:        "check",
+				Description: "Check whether the game servers are online.",
+				Handler:     c.check,
+			},
+			{
+				Name:        "list",
+				Description: "List the game servers this server checks.",
+				Handler:     c.list,
+			},
+			{
+				Name:        "add",
+				Description: "Add a game server to check.",
+				Permission:  manage,
+				Args: []router.Arg{
+					{Name: "name", Description: "Name to show, e.g. \"Minecraft Vanilla\"", Required: true},
+					{Name: "host", Description: "Host name or IP address", Required: true},
+					{Name: "port", Description: "Game port", Required: true, Type: router.ArgInteger, Min: 1, Max: 65535},
+					{Name: "protocol", Description: "How to check it, tcp by default", Choices: Protocols},
+					{Name: "query_port", Description: "A2S query port if it isn't the game port", Type: router.ArgInteger, Min: 1, Max: 65535},
+					{Name: "group", Description: "Heading to list it under, e.g. Minecraft Modded", Rest: true},
+				},
+				Usage:   "Usage: /servers add \"<name>\" <host> <port> [minecraft|a2s|tcp] [query_port] [group...]",
+				Handler: c.add,
+			},
+			{
+				Name:        "remove",
+				Description: "Stop checking a game server.",
+				Permission:  manage,
+				Args:        []router.Arg{{Name: "name", Description: "The server's name", Required: true, Rest: true}},
+				Handler:     c.remove,
+			},
+		},
+	})
+}
+
+func (c *Checker) servers(req *router.Request) []Server {
+	return c.inventories.List(req.GuildID)
+}
+
+func (c *Checker) check(req *router.Request) {
+	req.Reply(CheckServers(req.Context(), c.servers(req), c.cfg))
+}
+
+func (c *Checker) list(req *router.Request) {
+	servers := c.servers(req)
+	if len(servers) == 0 {
+		req.Reply("No game servers are checked here. Add one with /servers add.")
+		return
+	}
+	var b strings.Builder
+	names, byGroup := groups(servers)
+	for _, group := range names {
+		if group != "" {
+			b.WriteString("__" + group + "__\n")
+		}
+		for _, i := range byGroup[group] {
+			srv := servers[i]
+			line := fmt.Sprintf("**%s** `%s` %s", srv.Name, srv.Address(), srv.Protocol)
+			if srv.QueryPort != 0 {
+				line += fmt.Sprintf(", query port %d", srv.QueryPort)
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	req.Reply(b.String())
+}
+
+func (c *Checker) add(req *router.Request) {
+	if req.GuildID == "" {
+		req.Reply("Game servers can only be added in a server.")
+		return
+	}
+	port, err := strconv.Atoi(req.Arg(2))
+	if err != nil {
+		req.Reply("`" + req.Arg(2) + "` isn't a port number.")
+		return
+	}
+	srv := Server{
+		Name:     strings.TrimSpace(req.Arg(0)),
+		Host:     req.Arg(1),
+		Port:     port,
+		Protocol: Protocol(strings.ToLower(req.Arg(3))),
+		Group:    req.Arg(5),
+	}
+	if srv.Protocol == "" {
+		srv.Protocol = TCP
+	}
+	if q := req.Arg(4); q != "" {
+		if srv.QueryPort, err = strconv.Atoi(q); err != nil {
+			req.Reply("`" + q + "` isn't a port number.")
+			return
+		}
+	}
+	if err := c.inventories.Add(req.Context(), req.GuildID, srv); err != nil {
+		req.Reply("Failed to add server: " + err.Error())
+		return
+	}
+	req.Reply(fmt.Sprintf("Added **%s** (`%s`, %s). /servers will check it from now on.", srv.Name, srv.Address(), srv.Protocol))
+}
+
+func (c *Checker) remove(req *router.Request) {
+	if req.GuildID == "" {
+		req.Reply("Game servers can only be removed in a server.")
+		return
+	}
+	name := req.Arg(0)
+	found, err := c.inventories.Remove(req.GuildID, name)
+	if err != nil {
+		req.Reply("Failed to remove server: " + err.Error())
+		return
+	}
+	if !found {
+		req.Reply("No server called `" + name + "` is checked here. See /servers list.")
+		return
+	}
+	req.Reply("Removed **" + name + "**.")
+}
@@ -0,0 +1,218 @@
+package servercheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"discordBot/bot/ratelimit"
+	"discordBot/store"
+)
+
+const (
+	inventoryFile = "servers.json"
+	// maxServers is how many servers one server's inventory can hold.
+	maxServers = 25
+	// resolveTimeout bounds looking up a server's host when it's added.
+	resolveTimeout = 5 * time.Second
+)
+
+// Protocols lists the protocols a server can be probed with.
+var Protocols = []string{string(Minecraft), string(A2S), string(TCP)}
+
+// Config is the "servers" section of the bot config.
+type Config struct {
+	// Inventory is the servers every Discord server starts with, until it
+	// changes its own list with /servers add or remove.
+	Inventory []Server `json:"inventory"`
+	// Workers is how many servers are probed at once.
+	Workers int `json:"workers"`
+	// ProbeTimeout bounds each probe: the protocol's attempt and the TCP
+	// fallback share it.
+	ProbeTimeout ratelimit.Duration `json:"probe_timeout"`
+	// Deadline bounds a whole check. Servers not yet probed by then are
+	// shown as not checked, and those still being probed as not answering.
+	Deadline ratelimit.Duration `json:"deadline"`
+}
+
+// DefaultConfig checks the community's Minecraft and Valheim servers, four at
+// a time, giving each 5 seconds and the whole check 10.
+func DefaultConfig() Config {
+	const host = "65.21.132.169"
+	return Config{
+		Inventory: []Server{
+			{Name: "Minecraft Vanilla", Host: host, Port: 25565, Protocol: Minecraft, Group: "Minecraft"},
+			{Name: "Minecraft Modded: Society Sunlit Valley", Host: host, Port: 25566, Protocol: Minecraft, Group: "Minecraft"},
+			{Name: "Valheim", Host: host, Port: 2456, Protocol: A2S, QueryPort: 2457},
+		},
+		Workers:      4,
+		ProbeTimeout: ratelimit.Duration(5 * time.Second),
+		Deadline:     ratelimit.Duration(10 * time.Second),
+	}
+}
+
+// withDefaults fills in every timing left out of the config file.
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.Workers <= 0 {
+		c.Workers = d.Workers
+	}
+	if c.ProbeTimeout <= 0 {
+		c.ProbeTimeout = d.ProbeTimeout
+	}
+	if c.Deadline <= 0 {
+		c.Deadline = d.Deadline
+	}
+	return c
+}
+
+// Validate checks srv has what a probe needs.
+func (s Server) Validate() error {
+	switch {
+	case strings.TrimSpace(s.Name) == "":
+		return fmt.Errorf("a server needs a name")
+	case strings.TrimSpace(s.Host) == "":
+		return fmt.Errorf("%s needs a host", s.Name)
+	case s.Port < 1 || s.Port > 65535:
+		return fmt.Errorf("%s has port %d, use 1 to 65535", s.Name, s.Port)
+	case s.QueryPort < 0 || s.QueryPort > 65535:
+		return fmt.Errorf("%s has query port %d, use 1 to 65535", s.Name, s.QueryPort)
+	case !slices.Contains(Protocols, string(s.Protocol)):
+		return fmt.Errorf("%s has unknown protocol %q, use one of %s", s.Name, s.Protocol, strings.Join(Protocols, ", "))
+	}
+	return nil
+}
+
+// checkPublic resolves srv's host and refuses it unless every address is
+// public, so /servers add can't point the bot at itself or its network.
+func (s Server) checkPublic(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, s.Host)
+	if err != nil {
+		return fmt.Errorf("%s's host %s doesn't resolve", s.Name, s.Host)
+	}
+	for _, addr := range addrs {
+		if !public(addr.IP) {
+			return fmt.Errorf("%s's host %s is a local or private address", s.Name, s.Host)
+		}
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, 100.64.0.0/10.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// public reports whether ip is reachable across the internet rather than
+// this machine or its network.
+func public(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+// Inventories stores each Discord server's own list of game servers by guild
+// ID. A guild without one uses the configured inventory.
+type Inventories struct {
+	defaults []Server
+	file     *store.File[map[string][]Server]
+}
+
+// OpenInventories loads servers.json from the data directory. defaults is
+// the list guilds start with.
+func OpenInventories(defaults []Server) (*Inventories, error) {
+	file, err := store.Open(inventoryFile, make(map[string][]Server))
+	if err != nil {
+		return nil, err
+	}
+	return &Inventories{defaults: defaults, file: file}, nil
+}
+
+// List returns guildID's servers, in the order they were added. Those from
+// the configured inventory may be private.
+func (inv *Inventories) List(guildID string) []Server {
+	servers := slices.Clone(inv.defaults)
+	inv.file.View(func(all *map[string][]Server) {
+		if own, ok := (*all)[guildID]; ok {
+			servers = slices.Clone(own)
+		}
+	})
+	for i := range servers {
+		servers[i].AllowPrivate = slices.Contains(inv.defaults, servers[i])
+	}
+	return servers
+}
+
+// Add appends srv to guildID's servers once its host is known to be public.
+func (inv *Inventories) Add(ctx context.Context, guildID string, srv Server) error {
+	if err := srv.Validate(); err != nil {
+		return err
+	}
+	if err := srv.checkPublic(ctx); err != nil {
+		return err
+	}
+	return inv.file.Update(func(all *map[string][]Server) error {
+		servers, ok := (*all)[guildID]
+		if !ok {
+			servers = slices.Clone(inv.defaults)
+		}
+		if len(servers) >= maxServers {
+			return fmt.Errorf("this server already checks %d game servers, remove one first", maxServers)
+		}
+		if slices.IndexFunc(servers, sameName(srv.Name)) >= 0 {
+			return fmt.Errorf("there's already a server called %s", srv.Name)
+		}
+		(*all)[guildID] = append(servers, srv)
+		return nil
+	})
+}
+
+// Remove deletes the server called name from guildID's servers, reporting
+// whether there was one.
+func (inv *Inventories) Remove(guildID, name string) (bool, error) {
+	var found bool
+	err := inv.file.Update(func(all *map[string][]Server) error {
+		servers, ok := (*all)[guildID]
+		if !ok {
+			servers = slices.Clone(inv.defaults)
+		}
+		i := slices.IndexFunc(servers, sameName(name))
+		if i < 0 {
+			return nil
+		}
+		found = true
+		(*all)[guildID] = slices.Delete(servers, i, i+1)
+		return nil
+	})
+	return found, err
+}
+
+func sameName(name string) func(Server) bool {
+	return func(s Server) bool {
+		return strings.EqualFold(s.Name, strings.TrimSpace(name))
+	}
+}
+
+// groups splits servers by display group, in the order each group first
+// appears, as indexes into servers. Servers without a group come first.
+func groups(servers []Server) (names []string, byGroup map[string][]int) {
+	byGroup = make(map[string][]int)
+	for i, srv := range servers {
+		if _, ok := byGroup[srv.Group]; !ok {
+			names = append(names, srv.Group)
+		}
+		byGroup[srv.Group] = append(byGroup[srv.Group], i)
+	}
+	slices.SortStableFunc(names, func(a, b string) int {
+		switch {
+		case a == "" && b != "":
+			return -1
+		case b == "" && a != "":
+			return 1
+		}
+		return 0
+	})
+	return names, byGroup
+}
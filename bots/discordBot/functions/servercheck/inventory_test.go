@@ -0,0 +1,129 @@
+package servercheck
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"discordBot/bot/router"
+	"discordBot/bot/router/routertest"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		srv     Server
+		wantErr string
+	}{
+		{name: "ok", srv: Server{Name: "Valheim", Host: "65.21.132.169", Port: 2456, Protocol: A2S, QueryPort: 2457}},
+		// The operator's own servers may be on the same box or network.
+		{name: "private is fine", srv: Server{Name: "LAN", Host: "192.168.1.20", Port: 25565, Protocol: Minecraft}},
+		{name: "no name", srv: Server{Host: "65.21.132.169", Port: 80, Protocol: TCP}, wantErr: "needs a name"},
+		{name: "no host", srv: Server{Name: "Web", Port: 80, Protocol: TCP}, wantErr: "needs a host"},
+		{name: "bad port", srv: Server{Name: "Web", Host: "65.21.132.169", Port: 70000, Protocol: TCP}, wantErr: "use 1 to 65535"},
+		{name: "bad protocol", srv: Server{Name: "Web", Host: "65.21.132.169", Port: 80, Protocol: "http"}, wantErr: "unknown protocol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.srv.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() = %v, want no error", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPublic(t *testing.T) {
+	tests := []struct {
+		host    string
+		wantErr string
+	}{
+		{host: "65.21.132.169"},
+		{host: "2606:4700::1111"},
+		{host: "127.0.0.1", wantErr: "local or private"},
+		{host: "::1", wantErr: "local or private"},
+		{host: "192.168.1.1", wantErr: "local or private"},
+		{host: "10.0.0.5", wantErr: "local or private"},
+		{host: "100.64.0.1", wantErr: "local or private"},
+		{host: "100.127.255.254", wantErr: "local or private"},
+		{host: "169.254.169.254", wantErr: "local or private"},
+		{host: "fe80::1", wantErr: "local or private"},
+		{host: "0.0.0.0", wantErr: "local or private"},
+		{host: "nowhere.invalid", wantErr: "doesn't resolve"},
+	}
+	for _, tt := range tests {
+		err := Server{Name: "Test", Host: tt.host}.checkPublic(context.Background())
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("checkPublic(%s) = %v, want no error", tt.host, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("checkPublic(%s) = %v, want an error containing %q", tt.host, err, tt.wantErr)
+		}
+	}
+	if !public(net.ParseIP("100.128.0.1")) {
+		t.Error("100.128.0.1 is past the shared address space, want it public")
+	}
+}
+
+func TestListAllowsPrivateOnlyForConfiguredServers(t *testing.T) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	lan := Server{Name: "LAN", Host: "192.168.1.20", Port: 25565, Protocol: Minecraft}
+	inv, err := OpenInventories([]Server{lan})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Add(context.Background(), "300", Server{Name: "Web", Host: "65.21.132.169", Port: 80, Protocol: TCP}); err != nil {
+		t.Fatal(err)
+	}
+	got := inv.List("300")
+	if len(got) != 2 || !got[0].AllowPrivate || got[1].AllowPrivate {
+		t.Errorf("List = %+v, want only the configured server allowed private addresses", got)
+	}
+}
+
+func TestAddTakesAMultiWordGroup(t *testing.T) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	c, err := New(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := router.New()
+	c.Register(r, "manage_config")
+
+	msg := routertest.GuildMessage(`/servers add "Sunlit Valley" 65.21.132.169 25566 minecraft 0 Minecraft Modded`)
+	sent, _ := routertest.Run(r, msg)
+	if want := "Added **Sunlit Valley** (`65.21.132.169:25566`, minecraft). /servers will check it from now on."; len(sent) != 1 || sent[0].Content != want {
+		t.Fatalf("/servers add sent %+v, want %q", sent, want)
+	}
+	servers := c.inventories.List(msg.GuildID)
+	if got := servers[len(servers)-1]; got.Group != "Minecraft Modded" {
+		t.Errorf("group = %q, want Minecraft Modded", got.Group)
+	}
+}
+
+func TestNewAcceptsPrivateConfiguredServers(t *testing.T) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	cfg := Config{Inventory: []Server{{Name: "Local", Host: "127.0.0.1", Port: 25565, Protocol: Minecraft}}}
+	if _, err := New(cfg); err != nil {
+		t.Errorf("New refused a configured server on loopback: %v", err)
+	}
+}
+
+func TestAddRefusesPrivateHosts(t *testing.T) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	inv, err := OpenInventories(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = inv.Add(context.Background(), "300", Server{Name: "Self", Host: "127.0.0.1", Port: 22, Protocol: TCP})
+	if err == nil {
+		t.Fatal("Add accepted a loopback server")
+	}
+	if got := inv.List("300"); len(got) != 0 {
+		t.Errorf("List = %+v, want nothing added", got)
+	}
+}
@@ -1,87 +1,70 @@
 package servercheck
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-
-	"discordBot/util"
+	"strings"
+	"time"
 )
 
-// Server represents a game server to check
-type Server struct {
-	Name string
-	Port int
-}
-
-// ServerResponse represents the JSON structure returned by the serviceChecker binary
-type ServerResponse struct {
-	Name    string
-	Port    int
-	Address string `json:"address"`
-	Status  string `json:"status"`
-	Message string
-}
-
-// CheckServers calls the serviceChecker binary for each server, parses the JSON responses, and returns a user-friendly string
-func CheckServers() (string, error) {
-	ip := "65.21.132.169"
-	servers := []Server{
-		{"Minecraft Vanilla", 25565},
-		{"Minecraft Modded: Society Sunlit Valley", 25566},
-		{"Valheim", 2456},
-		// Add more servers as needed
+// CheckServers probes the servers concurrently within cfg's limits and
+// returns a user-friendly summary, grouped by display group. Servers the
+// deadline kept from being probed are listed as not checked.
+func CheckServers(ctx context.Context, servers []Server, cfg Config) string {
+	if len(servers) == 0 {
+		return "🔍 No servers to check. Add one with /servers add."
 	}
-
-	var responses []ServerResponse
-
-	for _, srv := range servers {
-		args := fmt.Sprintf("%s:%d", ip, srv.Port)
-		output, err := util.ExecBinary("./bin/serviceChecker", args)
-		if err != nil {
-			responses = append(responses, ServerResponse{
-				Name:   srv.Name,
-				Port:   srv.Port,
-				Status: "Error",
-			})
-			continue
-		}
-
-		var responsesFromBinary []ServerResponse
-		err = json.Unmarshal([]byte(output), &responsesFromBinary)
-		if err != nil || len(responsesFromBinary) == 0 {
-			responses = append(responses, ServerResponse{
-				Name:   srv.Name,
-				Port:   srv.Port,
-				Status: "Error",
-			})
-			continue
+	statuses := CheckAll(ctx, servers, cfg)
+	var b strings.Builder
+	b.WriteString("🔍 Server Check Results:\n")
+	names, byGroup := groups(servers)
+	for _, group := range names {
+		if group != "" {
+			b.WriteString("\n__" + group + "__\n")
 		}
-
-		// Take the first (and presumably only) response
-		response := responsesFromBinary[0]
-		// Ensure the response has the correct name and port
-		response.Name = srv.Name
-		response.Message = fmt.Sprintf("Status: %s", response.Status)
-		if response.Status == "Online" {
-			response.Message += fmt.Sprintf(", Address: %s", response.Address)
+		for _, i := range byGroup[group] {
+			b.WriteString(formatStatus(statuses[i]))
 		}
-		responses = append(responses, response)
 	}
+	return b.String()
+}
 
-	// Format the response in a user-friendly way
-	if len(responses) == 0 {
-		return "🔍 No servers to check.", nil
+// formatStatus describes one server, e.g. "🟢 **Minecraft Vanilla**: 1.21.1,
+// 3/20 players, 42ms" followed by its MOTD and address.
+func formatStatus(s Status) string {
+	if errors.Is(s.Err, ErrDeadline) {
+		return fmt.Sprintf("⏳ **%s**: not checked in time\n", s.Server.Name)
 	}
-
-	result := "🔍 Server Check Results:\n"
-	for _, resp := range responses {
-		address := resp.Address
-		if resp.Status == "online" {
-			result += fmt.Sprint(fmt.Sprintf("%v:\n%v\n```%v```\n", resp.Name, resp.Message, address))
-		} else {
-			result += fmt.Sprint(fmt.Sprintf("%v:\n%s\n", resp.Name, resp.Message))
-		}
-
+	if errors.Is(s.Err, ErrPrivateAddress) {
+		return fmt.Sprintf("⛔ **%s**: not checked, its host resolves to a local or private address\n", s.Server.Name)
+	}
+	if errors.Is(s.Err, ErrTimedOut) {
+		return fmt.Sprintf("🔴 **%s**: offline, no answer in time\n", s.Server.Name)
+	}
+	if !s.Online {
+		return fmt.Sprintf("🔴 **%s**: offline\n", s.Server.Name)
+	}
+	if s.Protocol == TCP && s.Server.Protocol != TCP {
+		return fmt.Sprintf("🟡 **%s**: port open but no %s reply, %s\n`%s`\n", s.Server.Name, s.Server.Protocol, latency(s.Latency), s.Server.Address())
+	}
+	var details []string
+	if s.Version != "" {
+		details = append(details, s.Version)
 	}
-	return result, nil
+	if s.Protocol != TCP {
+		details = append(details, fmt.Sprintf("%d/%d players", s.Players, s.MaxPlayers))
+	}
+	if s.Latency > 0 {
+		details = append(details, latency(s.Latency))
+	}
+	line := fmt.Sprintf("🟢 **%s**: %s\n", s.Server.Name, strings.Join(details, ", "))
+	if s.MOTD != "" {
+		line += "> " + strings.ReplaceAll(s.MOTD, "\n", "\n> ") + "\n"
+	}
+	return line + "`" + s.Server.Address() + "`\n"
+}
+
+func latency(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
 }
@@ -0,0 +1,209 @@
+package servercheck
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// slpProtocol is the protocol version sent in the handshake. -1 asks the
+	// server to answer whatever version it runs.
+	slpProtocol = -1
+	// maxPacket bounds a status response, which is a few KB even with an icon.
+	maxPacket = 1 << 21
+)
+
+// minecraftStatus is the JSON a server answers a status request with.
+type minecraftStatus struct {
+	Version struct {
+		Name string `json:"name"`
+	} `json:"version"`
+	Players struct {
+		Max    int `json:"max"`
+		Online int `json:"online"`
+	} `json:"players"`
+	Description json.RawMessage `json:"description"`
+}
+
+// pingMinecraft runs the Server List Ping: a handshake and status request,
+// then a ping whose round trip is the latency.
+func pingMinecraft(ctx context.Context, dialer *net.Dialer, host string, port int) (Status, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return Status{}, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	r := bufio.NewReader(conn)
+
+	var handshake bytes.Buffer
+	writeVarInt(&handshake, 0x00)
+	writeVarInt(&handshake, slpProtocol)
+	writeString(&handshake, host)
+	binary.Write(&handshake, binary.BigEndian, uint16(port))
+	writeVarInt(&handshake, 1)
+	if err := writePacket(conn, handshake.Bytes()); err != nil {
+		return Status{}, err
+	}
+	if err := writePacket(conn, []byte{0x00}); err != nil {
+		return Status{}, err
+	}
+	id, body, err := readPacket(r)
+	if err != nil {
+		return Status{}, err
+	}
+	if id != 0x00 {
+		return Status{}, fmt.Errorf("unexpected packet %#x instead of a status response", id)
+	}
+	payload, err := readString(bytes.NewReader(body))
+	if err != nil {
+		return Status{}, err
+	}
+	var ms minecraftStatus
+	if err := json.Unmarshal([]byte(payload), &ms); err != nil {
+		return Status{}, fmt.Errorf("unreadable status: %w", err)
+	}
+	status := Status{
+		Online:     true,
+		Protocol:   Minecraft,
+		Version:    ms.Version.Name,
+		MOTD:       chatText(ms.Description),
+		Players:    ms.Players.Online,
+		MaxPlayers: ms.Players.Max,
+	}
+
+	var ping bytes.Buffer
+	writeVarInt(&ping, 0x01)
+	sent := time.Now()
+	binary.Write(&ping, binary.BigEndian, sent.UnixMilli())
+	if err := writePacket(conn, ping.Bytes()); err != nil {
+		return status, nil
+	}
+	if id, _, err := readPacket(r); err == nil && id == 0x01 {
+		status.Latency = time.Since(sent)
+	}
+	return status, nil
+}
+
+// chatText flattens a MOTD, which is either a plain string or a chat
+// component with nested extras, and strips § formatting codes.
+func chatText(raw json.RawMessage) string {
+	text := flatten(raw)
+	var b strings.Builder
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '§' {
+			i++
+			continue
+		}
+		b.WriteRune(runes[i])
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// flatten joins a chat component's text and its extras' in order. The spaces
+// between components are kept; only the whole MOTD is trimmed.
+func flatten(raw json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var component struct {
+		Text  string            `json:"text"`
+		Extra []json.RawMessage `json:"extra"`
+	}
+	if json.Unmarshal(raw, &component) != nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(component.Text)
+	for _, extra := range component.Extra {
+		b.WriteString(flatten(extra))
+	}
+	return b.String()
+}
+
+func writePacket(w io.Writer, data []byte) error {
+	var frame bytes.Buffer
+	writeVarInt(&frame, int32(len(data)))
+	frame.Write(data)
+	_, err := w.Write(frame.Bytes())
+	return err
+}
+
+func readPacket(r *bufio.Reader) (int32, []byte, error) {
+	length, err := readVarInt(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if length <= 0 || length > maxPacket {
+		return 0, nil, fmt.Errorf("bad packet length %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return 0, nil, err
+	}
+	body := bytes.NewReader(data)
+	id, err := readVarInt(body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, data[len(data)-body.Len():], nil
+}
+
+func writeVarInt(w *bytes.Buffer, value int32) {
+	v := uint32(value)
+	for {
+		if v&^0x7f == 0 {
+			w.WriteByte(byte(v))
+			return
+		}
+		w.WriteByte(byte(v&0x7f | 0x80))
+		v >>= 7
+	}
+}
+
+func readVarInt(r io.ByteReader) (int32, error) {
+	var v uint32
+	for i := 0; i < 5; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		v |= uint32(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			return int32(v), nil
+		}
+	}
+	return 0, errors.New("varint too long")
+}
+
+func writeString(w *bytes.Buffer, s string) {
+	writeVarInt(w, int32(len(s)))
+	w.WriteString(s)
+}
+
+func readString(r *bytes.Reader) (string, error) {
+	n, err := readVarInt(r)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || int(n) > r.Len() {
+		return "", fmt.Errorf("bad string length %d", n)
+	}
+	buf := make([]byte, n)
+	io.ReadFull(r, buf)
+	return string(buf), nil
+}
@@ -0,0 +1,196 @@
+package servercheck
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Protocol is how a server is probed.
+type Protocol string
+
+const (
+	// Minecraft is the Java Edition Server List Ping.
+	Minecraft Protocol = "minecraft"
+	// A2S is the Steam server query used by Source engine games and Valheim.
+	A2S Protocol = "a2s"
+	// TCP only checks the port accepts connections.
+	TCP Protocol = "tcp"
+)
+
+// ErrDeadline is a Status's Err when the check's deadline passed before the
+// server was probed.
+var ErrDeadline = errors.New("not checked before the deadline")
+
+// ErrPrivateAddress is a Status's Err when the server's host resolved to an
+// address only the operator's own servers may be probed at.
+var ErrPrivateAddress = errors.New("resolves to a local or private address")
+
+// ErrTimedOut is a Status's Err when the server was probed but hadn't
+// answered by the time the probe or the check ran out.
+var ErrTimedOut = errors.New("no answer in time")
+
+// Server is a game server to check.
+type Server struct {
+	Name     string   `json:"name"`
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Protocol Protocol `json:"protocol"`
+	// QueryPort is where A2S is asked when it isn't the game port, e.g. the
+	// game port + 1 for Valheim.
+	QueryPort int `json:"query_port,omitempty"`
+	// Group is the heading the server is listed under.
+	Group string `json:"group,omitempty"`
+	// AllowPrivate lets probes reach loopback and private addresses. Only
+	// servers from the operator's config have it; ones added with /servers
+	// add may only be probed at public addresses.
+	AllowPrivate bool `json:"-"`
+}
+
+// Address is "host:port".
+func (s Server) Address() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+// Status is what a probe found out about a server.
+type Status struct {
+	Server Server
+	Online bool
+	// Protocol is the one that answered: TCP when the server's own protocol
+	// didn't but the port accepted a connection.
+	Protocol Protocol
+	Version  string
+	// MOTD is a Minecraft server's message of the day or an A2S server's name.
+	MOTD                string
+	Players, MaxPlayers int
+	Latency             time.Duration
+	// Err is why the server's protocol didn't answer, if it didn't.
+	Err error
+}
+
+// Probe checks srv with its protocol, falling back to a TCP connect to the
+// game port if that doesn't answer. Both share one timeout: the connect is
+// made alongside the protocol's attempt, not after it has used the time up.
+// A server still silent when the timeout or ctx ends has ErrTimedOut.
+func Probe(ctx context.Context, srv Server, timeout time.Duration) Status {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	dialer := srv.dialer()
+	type dialed struct {
+		status Status
+		err    error
+	}
+	fallback := make(chan dialed, 1)
+	go func() {
+		status, err := dialTCP(ctx, dialer, srv.Host, srv.Port)
+		fallback <- dialed{status, err}
+	}()
+
+	var status Status
+	var err error
+	switch srv.Protocol {
+	case Minecraft:
+		status, err = pingMinecraft(ctx, dialer, srv.Host, srv.Port)
+	case A2S:
+		port := srv.QueryPort
+		if port == 0 {
+			port = srv.Port
+		}
+		status, err = queryA2S(ctx, dialer, srv.Host, port)
+	}
+	if srv.Protocol == TCP || err != nil {
+		d := <-fallback
+		status = d.status
+		if err == nil {
+			err = d.err
+		}
+	}
+	if !status.Online && (ctx.Err() != nil || timedOut(err)) {
+		err = ErrTimedOut
+	}
+	status.Server, status.Err = srv, err
+	return status
+}
+
+// CheckAll probes servers with up to cfg.Workers at once, each within
+// cfg.ProbeTimeout, and gives up on the rest at cfg.Deadline. The statuses are
+// in the same order as servers; those never probed have ErrDeadline, and
+// those cut off mid-probe ErrTimedOut.
+func CheckAll(ctx context.Context, servers []Server, cfg Config) []Status {
+	deadline := time.Now().Add(time.Duration(cfg.Deadline))
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	statuses := make([]Status, len(servers))
+	for i, srv := range servers {
+		statuses[i] = Status{Server: srv, Err: ErrDeadline}
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(cfg.Workers, len(servers)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// A job can be taken as the deadline passes, even a moment
+				// before ctx notices; it was never probed, so it keeps
+				// ErrDeadline.
+				if ctx.Err() != nil || !time.Now().Before(deadline) {
+					continue
+				}
+				statuses[i] = Probe(ctx, servers[i], time.Duration(cfg.ProbeTimeout))
+			}
+		}()
+	}
+feed:
+	for i := range servers {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return statuses
+}
+
+// dialer connects to the server for every probe. Unless the server may be
+// private, it refuses any address its host resolves to that isn't public, so
+// a host repointed after it was added still can't reach the bot's network.
+func (s Server) dialer() *net.Dialer {
+	if s.AllowPrivate {
+		return &net.Dialer{}
+	}
+	return &net.Dialer{Control: func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !public(ip) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}}
+}
+
+// timedOut reports whether err is a read or dial that ran past the deadline
+// the probe's context set on it.
+func timedOut(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// dialTCP reports the server online if its port accepts a connection.
+func dialTCP(ctx context.Context, dialer *net.Dialer, host string, port int) (Status, error) {
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return Status{}, err
+	}
+	conn.Close()
+	return Status{Online: true, Protocol: TCP, Latency: time.Since(start)}, nil
+}
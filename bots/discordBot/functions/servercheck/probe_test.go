@@ -0,0 +1,358 @@
+package servercheck
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"discordBot/bot/ratelimit"
+)
+
+// listenTCP starts a TCP listener on loopback that hands every connection to
+// serve, and returns its port.
+func listenTCP(t *testing.T, serve func(net.Conn)) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// closedPort returns a loopback port nothing is listening on.
+func closedPort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	return port
+}
+
+// handshake is what a Server List Ping client sent before its status request.
+type handshake struct {
+	protocol, next int32
+	host           string
+	port           uint16
+}
+
+// fakeMinecraft answers the Server List Ping with status and echoes the ping,
+// sending the handshake it got to handshakes.
+func fakeMinecraft(status string, handshakes chan<- handshake) func(net.Conn) {
+	return func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		id, body, err := readPacket(r)
+		if err != nil || id != 0x00 {
+			return
+		}
+		var h handshake
+		b := bytes.NewReader(body)
+		h.protocol, _ = readVarInt(b)
+		h.host, _ = readString(b)
+		binary.Read(b, binary.BigEndian, &h.port)
+		h.next, _ = readVarInt(b)
+		handshakes <- h
+
+		if id, _, err := readPacket(r); err != nil || id != 0x00 {
+			return
+		}
+		var response bytes.Buffer
+		writeVarInt(&response, 0x00)
+		writeString(&response, status)
+		writePacket(conn, response.Bytes())
+
+		id, body, err = readPacket(r)
+		if err != nil || id != 0x01 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+		writePacket(conn, append([]byte{0x01}, body...))
+	}
+}
+
+func TestPingMinecraft(t *testing.T) {
+	handshakes := make(chan handshake, 1)
+	status := `{"version":{"name":"1.21.1","protocol":767},"players":{"max":20,"online":3},
+		"description":{"text":"§aSunlit ","extra":[{"text":"§lValley"},{"text":" ","extra":["§7modded"]}]}}`
+	port := listenTCP(t, fakeMinecraft(status, handshakes))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	got, err := pingMinecraft(ctx, &net.Dialer{}, "127.0.0.1", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := <-handshakes
+	if h != (handshake{protocol: slpProtocol, host: "127.0.0.1", port: uint16(port), next: 1}) {
+		t.Errorf("handshake = %+v, want protocol -1, the host and port and next state 1", h)
+	}
+	want := Status{Online: true, Protocol: Minecraft, Version: "1.21.1", MOTD: "Sunlit Valley modded", Players: 3, MaxPlayers: 20}
+	latency := got.Latency
+	got.Latency = 0
+	if got != want {
+		t.Errorf("status = %+v, want %+v", got, want)
+	}
+	if latency < 5*time.Millisecond {
+		t.Errorf("latency = %v, want the ping's round trip", latency)
+	}
+}
+
+func TestChatText(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "plain string", raw: `"A Minecraft Server"`, want: "A Minecraft Server"},
+		{name: "formatting codes", raw: `"§6§lGold§r server\n§7line two"`, want: "Gold server\nline two"},
+		{name: "component", raw: `{"text":"Hello"}`, want: "Hello"},
+		{name: "extras", raw: `{"text":"","extra":[{"text":"§cRed "},"plain ",{"text":"nested","extra":[{"text":" deep"}]}]}`, want: "Red plain nested deep"},
+		{name: "not text", raw: `42`, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chatText(json.RawMessage(tt.raw)); got != tt.want {
+				t.Errorf("chatText(%s) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+// a2sInfoReplyFor builds an A2S_INFO reply for a server called name.
+func a2sInfoReplyFor(name string, appID uint16, players, max byte, version string) []byte {
+	var b bytes.Buffer
+	b.Write(a2sHeader)
+	b.WriteByte(a2sInfoReply)
+	b.WriteByte(17) // protocol
+	for _, s := range []string{name, "de_dust2", "csgo", "Counter-Strike"} {
+		b.WriteString(s + "\x00")
+	}
+	binary.Write(&b, binary.LittleEndian, appID)
+	b.Write([]byte{players, max, 0, 'd', 'l', 0, 1})
+	if appID == theShip {
+		b.Write([]byte{0, 3, 60})
+	}
+	b.WriteString(version + "\x00")
+	return b.Bytes()
+}
+
+// fakeA2S answers A2S_INFO on a loopback UDP port, first with a challenge
+// that the next request has to carry, and returns the port.
+func fakeA2S(t *testing.T, reply []byte) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	challenge := []byte{0x0a, 0x0b, 0x0c, 0x0d}
+	query := append(append([]byte{}, a2sHeader...), append([]byte{a2sInfo}, "Source Engine Query\x00"...)...)
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			request := buf[:n]
+			switch {
+			case bytes.Equal(request, query):
+				conn.WriteTo(append(append([]byte{}, a2sHeader...), append([]byte{a2sChallenge}, challenge...)...), addr)
+			case bytes.Equal(request, append(query, challenge...)):
+				conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestQueryA2S(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply []byte
+		want  Status
+	}{
+		{
+			name:  "challenge then info",
+			reply: a2sInfoReplyFor("Valheim: Viking Hall", 0, 4, 10, "0.218.21"),
+			want:  Status{Online: true, Protocol: A2S, Version: "0.218.21", MOTD: "Valheim: Viking Hall", Players: 4, MaxPlayers: 10},
+		},
+		{
+			name:  "The Ship's extra fields",
+			reply: a2sInfoReplyFor("Ship", theShip, 1, 8, "1.0.0.5"),
+			want:  Status{Online: true, Protocol: A2S, Version: "1.0.0.5", MOTD: "Ship", Players: 1, MaxPlayers: 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port := fakeA2S(t, tt.reply)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			got, err := queryA2S(ctx, &net.Dialer{}, "127.0.0.1", port)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Latency <= 0 {
+				t.Errorf("latency = %v, want the answered request's round trip", got.Latency)
+			}
+			got.Latency = 0
+			if got != tt.want {
+				t.Errorf("status = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeFallsBackToTCP(t *testing.T) {
+	// The port accepts connections but never speaks the protocol.
+	silent := listenTCP(t, func(conn net.Conn) {})
+	srv := Server{Name: "Quiet", Host: "127.0.0.1", Port: silent, Protocol: Minecraft, AllowPrivate: true}
+
+	got := Probe(context.Background(), srv, 2*time.Second)
+	if !got.Online || got.Protocol != TCP || got.Err == nil {
+		t.Fatalf("Probe = %+v, want online over TCP with the Minecraft error kept", got)
+	}
+	if line := formatStatus(got); !strings.HasPrefix(line, "🟡 **Quiet**: port open but no minecraft reply") {
+		t.Errorf("formatStatus = %q, want the port open line", line)
+	}
+
+	closed := Server{Name: "Gone", Host: "127.0.0.1", Port: closedPort(t), Protocol: Minecraft, AllowPrivate: true}
+	got = Probe(context.Background(), closed, 2*time.Second)
+	if got.Online || errors.Is(got.Err, ErrDeadline) {
+		t.Errorf("Probe of a closed port = %+v, want offline", got)
+	}
+	if line := formatStatus(got); line != "🔴 **Gone**: offline\n" {
+		t.Errorf("formatStatus = %q, want offline", line)
+	}
+}
+
+// silentUDP returns a loopback UDP port that reads queries and never answers.
+func silentUDP(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 1400)
+		for {
+			if _, _, err := conn.ReadFrom(buf); err != nil {
+				return
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+func TestProbeSharesOneTimeout(t *testing.T) {
+	const timeout = 300 * time.Millisecond
+
+	// The fallback connect still lands while the protocol hangs.
+	silent := listenTCP(t, func(conn net.Conn) { time.Sleep(time.Second) })
+	start := time.Now()
+	got := Probe(context.Background(), Server{Name: "Quiet", Host: "127.0.0.1", Port: silent, Protocol: Minecraft, AllowPrivate: true}, timeout)
+	if elapsed := time.Since(start); elapsed > timeout+200*time.Millisecond {
+		t.Errorf("Probe took %v, want both attempts within the %v timeout", elapsed, timeout)
+	}
+	if !got.Online || got.Protocol != TCP {
+		t.Errorf("Probe = %+v, want online over TCP", got)
+	}
+
+	srv := Server{Name: "Mute", Host: "127.0.0.1", Port: closedPort(t), QueryPort: silentUDP(t), Protocol: A2S, AllowPrivate: true}
+	start = time.Now()
+	got = Probe(context.Background(), srv, timeout)
+	if elapsed := time.Since(start); elapsed > timeout+200*time.Millisecond {
+		t.Errorf("Probe took %v, want both attempts within the %v timeout", elapsed, timeout)
+	}
+	if got.Online || !errors.Is(got.Err, ErrTimedOut) {
+		t.Errorf("Probe = %+v, want offline with %v", got, ErrTimedOut)
+	}
+	if line := formatStatus(got); line != "🔴 **Mute**: offline, no answer in time\n" {
+		t.Errorf("formatStatus = %q, want the timed out line", line)
+	}
+}
+
+func TestCheckAllOnlyMarksUnprobedAsNotChecked(t *testing.T) {
+	servers := []Server{
+		{Name: "Cut off", Host: "127.0.0.1", Port: closedPort(t), QueryPort: silentUDP(t), Protocol: A2S, AllowPrivate: true},
+		{Name: "Never probed", Host: "127.0.0.1", Port: closedPort(t), QueryPort: silentUDP(t), Protocol: A2S, AllowPrivate: true},
+	}
+	cfg := Config{Workers: 1, ProbeTimeout: ratelimit.Duration(5 * time.Second), Deadline: ratelimit.Duration(300 * time.Millisecond)}
+	got := CheckAll(context.Background(), servers, cfg)
+	if !errors.Is(got[0].Err, ErrTimedOut) {
+		t.Errorf("%s: Err = %v, want %v", servers[0].Name, got[0].Err, ErrTimedOut)
+	}
+	if !errors.Is(got[1].Err, ErrDeadline) {
+		t.Errorf("%s: Err = %v, want %v", servers[1].Name, got[1].Err, ErrDeadline)
+	}
+	if line := formatStatus(got[1]); line != "⏳ **Never probed**: not checked in time\n" {
+		t.Errorf("formatStatus = %q, want the not checked line", line)
+	}
+}
+
+func TestProbeRefusesPrivateAddresses(t *testing.T) {
+	handshakes := make(chan handshake, 1)
+	port := listenTCP(t, fakeMinecraft(`{"version":{"name":"1.21.1"},"players":{"max":20,"online":3},"description":""}`, handshakes))
+	for _, host := range []string{"127.0.0.1", "localhost"} {
+		srv := Server{Name: "Sneaky", Host: host, Port: port, Protocol: Minecraft}
+		got := Probe(context.Background(), srv, 2*time.Second)
+		if got.Online || !errors.Is(got.Err, ErrPrivateAddress) {
+			t.Errorf("Probe of %s = %+v, want refused with %v", host, got, ErrPrivateAddress)
+		}
+		if line := formatStatus(got); line != "⛔ **Sneaky**: not checked, its host resolves to a local or private address\n" {
+			t.Errorf("formatStatus = %q, want the refused line", line)
+		}
+	}
+	select {
+	case h := <-handshakes:
+		t.Errorf("the server was reached anyway: %+v", h)
+	default:
+	}
+}
+
+func TestCheckServers(t *testing.T) {
+	handshakes := make(chan handshake, 1)
+	mc := listenTCP(t, fakeMinecraft(`{"version":{"name":"1.21.1"},"players":{"max":20,"online":3},"description":"§aWelcome"}`, handshakes))
+	a2s := fakeA2S(t, a2sInfoReplyFor("Valheim", 0, 0, 10, "0.218.21"))
+	tcp := listenTCP(t, func(conn net.Conn) {})
+	servers := []Server{
+		{Name: "Vanilla", Host: "127.0.0.1", Port: mc, Protocol: Minecraft, Group: "Minecraft", AllowPrivate: true},
+		{Name: "Valheim", Host: "127.0.0.1", Port: a2s - 1, QueryPort: a2s, Protocol: A2S, AllowPrivate: true},
+		{Name: "Web", Host: "127.0.0.1", Port: tcp, Protocol: TCP, AllowPrivate: true},
+	}
+	got := CheckServers(context.Background(), servers, DefaultConfig())
+
+	for _, want := range []string{
+		"🔍 Server Check Results:\n🟢 **Valheim**: 0.218.21, 0/10 players, ",
+		"🟢 **Web**: ",
+		"\n__Minecraft__\n🟢 **Vanilla**: 1.21.1, 3/20 players, ",
+		"> Welcome\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("CheckServers =\n%s\nwant it to contain %q", got, want)
+		}
+	}
+}
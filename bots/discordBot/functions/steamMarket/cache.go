@@ -0,0 +1,224 @@
+package steammarket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"discordBot/bot/ratelimit"
+	"discordBot/util"
+)
+
+const (
+	// fetchTimeout bounds one upstream request. Coalesced callers share it, so
+	// it isn't tied to any one caller's context.
+	fetchTimeout = 15 * time.Second
+	// minBackoff and maxBackoff bound how long we stop asking Steam after a 429.
+	minBackoff = 30 * time.Second
+	maxBackoff = 10 * time.Minute
+	// pruneEvery is how many stores go by between sweeps for dead entries.
+	pruneEvery = 500
+)
+
+// Config is the "market" section of the bot config.
+type Config struct {
+	// CacheTTL is how long a price is served without asking Steam again.
+	CacheTTL ratelimit.Duration `json:"cache_ttl"`
+	// MaxStale is how long after CacheTTL an old price is still returned
+	// straight away while a fresh one is fetched in the background.
+	MaxStale ratelimit.Duration `json:"max_stale"`
+	// Watched items are priced every SampleEvery so their history has no gaps.
+	Watched     []string           `json:"watched,omitempty"`
+	SampleEvery ratelimit.Duration `json:"sample_every"`
+	// WatchEvery is how often prices are checked against users' /watch targets.
+	WatchEvery ratelimit.Duration `json:"watch_every"`
+	// SteamRate caps how fast the bot calls the Steam Market, across every command.
+	SteamRate ratelimit.Limit `json:"steam_rate"`
+	// CommunityURL replaces https://steamcommunity.com, e.g. to test against a
+	// local server serving canned responses.
+	CommunityURL string `json:"community_url,omitempty"`
+}
+
+// DefaultConfig keeps prices for five minutes, serves them stale for an hour,
+// samples watched items hourly, checks /watch targets every 15 minutes and
+// asks Steam for at most one price every 3 seconds.
+func DefaultConfig() Config {
+	return Config{
+		CacheTTL:    ratelimit.Duration(5 * time.Minute),
+		MaxStale:    ratelimit.Duration(time.Hour),
+		SampleEvery: ratelimit.Duration(time.Hour),
+		WatchEvery:  ratelimit.Duration(15 * time.Minute),
+		SteamRate:   ratelimit.Limit{Every: ratelimit.Duration(3 * time.Second), Burst: 5},
+	}
+}
+
+// withDefaults fills in every setting left out of the config file.
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.CacheTTL == 0 {
+		c.CacheTTL = d.CacheTTL
+	}
+	if c.MaxStale == 0 {
+		c.MaxStale = d.MaxStale
+	}
+	if c.SampleEvery == 0 {
+		c.SampleEvery = d.SampleEvery
+	}
+	if c.WatchEvery == 0 {
+		c.WatchEvery = d.WatchEvery
+	}
+	if c.SteamRate == (ratelimit.Limit{}) {
+		c.SteamRate = d.SteamRate
+	}
+	return c
+}
+
+// Fetcher looks up one price overview upstream.
+type Fetcher func(ctx context.Context, key Key) (Overview, error)
+
+// Price is a cached overview and when it was fetched.
+type Price struct {
+	Overview
+	Fetched time.Time
+	// Stale is set when the price is older than the cache TTL, because Steam
+	// is rate limiting us or a refresh is still on its way.
+	Stale bool
+}
+
+type cacheEntry struct {
+	overview Overview
+	fetched  time.Time
+}
+
+// call is one upstream request that any number of callers can wait on.
+type call struct {
+	done  chan struct{}
+	price Price
+	err   error
+}
+
+// Cache serves price overviews from memory, coalescing concurrent lookups of
+// the same key into one upstream request and backing off when Steam returns 429.
+type Cache struct {
+	fetch    Fetcher
+	clock    ratelimit.Clock
+	ttl      time.Duration
+	maxStale time.Duration
+
+	mu      sync.Mutex
+	entries map[Key]cacheEntry
+	calls   map[Key]*call
+	stores  int
+	// backoff is the current wait after a 429; retryAt is when it ends.
+	backoff time.Duration
+	retryAt time.Time
+}
+
+func NewCache(cfg Config, fetch Fetcher, clock ratelimit.Clock) *Cache {
+	return &Cache{
+		fetch:    fetch,
+		clock:    clock,
+		ttl:      time.Duration(cfg.CacheTTL),
+		maxStale: time.Duration(cfg.MaxStale),
+		entries:  make(map[Key]cacheEntry),
+		calls:    make(map[Key]*call),
+	}
+}
+
+// Get returns the price for key. A fresh cached price is returned as is. A
+// price within MaxStale of expiring is returned marked stale while a refresh
+// runs in the background. Otherwise Get waits for Steam, falling back to
+// whatever old price it has if Steam fails.
+func (c *Cache) Get(ctx context.Context, key Key) (Price, error) {
+	c.mu.Lock()
+	now := c.clock.Now()
+	entry, cached := c.entries[key]
+	age := now.Sub(entry.fetched)
+	switch {
+	case cached && age < c.ttl:
+		c.mu.Unlock()
+		return Price{Overview: entry.overview, Fetched: entry.fetched}, nil
+	case cached && age < c.ttl+c.maxStale:
+		if !now.Before(c.retryAt) {
+			c.start(key)
+		}
+		c.mu.Unlock()
+		return Price{Overview: entry.overview, Fetched: entry.fetched, Stale: true}, nil
+	}
+	if now.Before(c.retryAt) {
+		retry := c.retryAt.Sub(now)
+		c.mu.Unlock()
+		if cached {
+			return Price{Overview: entry.overview, Fetched: entry.fetched, Stale: true}, nil
+		}
+		return Price{}, &RateLimitedError{RetryAfter: retry}
+	}
+	cl := c.start(key)
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.price, cl.err
+	case <-ctx.Done():
+		return Price{}, ctx.Err()
+	}
+}
+
+// start returns the running request for key, starting one if there is none.
+// c.mu must be held.
+func (c *Cache) start(key Key) *call {
+	if cl, ok := c.calls[key]; ok {
+		return cl
+	}
+	cl := &call{done: make(chan struct{})}
+	c.calls[key] = cl
+	go c.run(key, cl)
+	return cl
+}
+
+func (c *Cache) run(key Key, cl *call) {
+	logger := util.LoggerInit("STEAMMARKET", "Cache")
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	overview, err := c.fetch(ctx, key)
+	cancel()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defer close(cl.done)
+	delete(c.calls, key)
+	now := c.clock.Now()
+
+	var limited *RateLimitedError
+	if errors.As(err, &limited) {
+		c.backoff = min(max(c.backoff*2, minBackoff, limited.RetryAfter), maxBackoff)
+		c.retryAt = now.Add(c.backoff)
+		logger.Warn("Steam rate limited price lookups, backing off", "item", key.MarketHashName, "backoff", c.backoff)
+	}
+	if err != nil {
+		if entry, ok := c.entries[key]; ok {
+			cl.price = Price{Overview: entry.overview, Fetched: entry.fetched, Stale: true}
+			return
+		}
+		cl.err = err
+		return
+	}
+
+	c.backoff = 0
+	c.entries[key] = cacheEntry{overview: overview, fetched: now}
+	c.prune(now)
+	cl.price = Price{Overview: overview, Fetched: now}
+}
+
+// prune drops entries too old to be served, every so often.
+func (c *Cache) prune(now time.Time) {
+	c.stores++
+	if c.stores%pruneEvery != 0 {
+		return
+	}
+	for key, entry := range c.entries {
+		if now.Sub(entry.fetched) >= c.ttl+c.maxStale {
+			delete(c.entries, key)
+		}
+	}
+}
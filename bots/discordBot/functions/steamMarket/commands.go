@@ -0,0 +1,797 @@
+package steammarket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"discordBot/bot/ratelimit"
+	"discordBot/bot/router"
+	"discordBot/chart"
+	"discordBot/util"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// defaultHistoryDays is how far back /pricehistory looks without [days].
+	defaultHistoryDays = 30
+	maxHistoryDays     = 365
+	// sampleSpacing is the pause between watched items so sampling doesn't burst.
+	sampleSpacing = 3 * time.Second
+	chartWidth    = 800
+	chartHeight   = 400
+)
+
+// Market is the Steam Market module: the price cache, the price history it
+// feeds, users' watchlists and the jobs that keep them all up to date.
+type Market struct {
+	cfg     Config
+	steam   *steamClient
+	prices  *Cache
+	history *History
+	watches *Watchlists
+	prefs   *Preferences
+	names   *ItemNames
+	folios  *Portfolios
+	// throttle waits until another Steam Market request is allowed.
+	throttle func(context.Context) error
+	// guildDefaults returns a server's default game and currency.
+	guildDefaults func(guildID string) Defaults
+}
+
+// New opens the market's stores and sets up the price cache. guildDefaults
+// looks up a server's default game and currency; it may be nil.
+func New(cfg Config, guildDefaults func(guildID string) Defaults) (*Market, error) {
+	cfg = cfg.withDefaults()
+	history, err := OpenHistory()
+	if err != nil {
+		return nil, err
+	}
+	watches, err := OpenWatchlists()
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := OpenPreferences()
+	if err != nil {
+		return nil, err
+	}
+	names, err := OpenItemNames()
+	if err != nil {
+		return nil, err
+	}
+	folios, err := OpenPortfolios()
+	if err != nil {
+		return nil, err
+	}
+	steam := newSteamClient(cfg.CommunityURL)
+	wait := throttle(ratelimit.NewLimiter(ratelimit.SystemClock), cfg.SteamRate)
+	fetch := throttled(steam.overview, wait)
+	return &Market{
+		cfg:           cfg,
+		steam:         steam,
+		prices:        NewCache(cfg, history.recording(fetch, ratelimit.SystemClock), ratelimit.SystemClock),
+		history:       history,
+		watches:       watches,
+		prefs:         prefs,
+		names:         names,
+		folios:        folios,
+		throttle:      wait,
+		guildDefaults: guildDefaults,
+	}, nil
+}
+
+// Register adds the Steam Market commands to r.
+func (m *Market) Register(r *router.Router) {
+	// The item takes the rest of a text command's words. /price and
+	// /pricehistory take their options back off the end of it.
+	itemArg := router.Arg{
+		Name:         "item_name",
+		Description:  "Market name or a close match, e.g. ak redline ft",
+		Required:     true,
+		Rest:         true,
+		Autocomplete: m.suggestItems,
+	}
+	gameArg := router.Arg{Name: "game", Description: "cs2, dota2, tf2, rust or a Steam app ID", Autocomplete: suggestGames}
+	currencyArg := router.Arg{Name: "currency", Description: "Currency code, e.g. GBP", Choices: CurrencyCodes()}
+	r.Register(router.Command{
+		Name:        "price",
+		Module:      "market",
+		Args:        []router.Arg{itemArg, gameArg, currencyArg},
+		Usage:       "Usage: /price <item_name...> [game] [currency], e.g. /price ak redline ft cs2 EUR",
+		Description: "Fetches the price overview for the specified Steam Market item.",
+		Handler:     m.price,
+	})
+	r.Register(router.Command{
+		Name:   "pricehistory",
+		Module: "market",
+		Args: []router.Arg{
+			itemArg,
+			{Name: "days", Description: "How many days back to show", Type: router.ArgInteger, Min: 1, Max: maxHistoryDays},
+		},
+		Usage:       "Usage: /pricehistory <item_name...> [days], e.g. /pricehistory ak redline ft 90",
+		Description: "Charts the recorded price of a Steam Market item.",
+		Handler:     m.priceHistory,
+	})
+	r.Register(router.Command{
+		Name:        "watch",
+		Module:      "market",
+		Description: "Get a DM when an item's price crosses a target.",
+		Subcommands: []*router.Command{
+			{
+				Name:        "add",
+				Description: "Watch an item's lowest price.",
+				Args: []router.Arg{
+					{Name: "direction", Description: "Alert when the price goes below or above the target", Required: true, Choices: []string{"below", "above"}},
+					{Name: "price", Description: "Target price, e.g. 12.50", Required: true},
+					itemArg,
+				},
+				Usage:   "Usage: /watch add below|above <price> <item_name...>, e.g. /watch add below 12.50 ak redline ft",
+				Handler: m.watchAdd,
+			},
+			{
+				Name:        "list",
+				Description: "Show your watches.",
+				Handler:     m.watchList,
+			},
+			{
+				Name:        "remove",
+				Description: "Stop watching an item.",
+				Args:        []router.Arg{{Name: "number", Description: "Number from /watch list", Type: router.ArgInteger, Required: true, Min: 1}},
+				Handler:     m.watchRemove,
+			},
+		},
+	})
+	r.Register(router.Command{
+		Name:   "inventory",
+		Module: "market",
+		Args: []router.Arg{
+			{Name: "profile", Description: "SteamID64 or steamcommunity.com profile link", Required: true},
+			{Name: "appid", Description: "cs2, dota2, tf2, rust or a Steam app ID", Autocomplete: suggestGames},
+		},
+		Description: "Values a public Steam inventory at market prices.",
+		Handler:     m.inventory,
+	})
+	r.Register(router.Command{
+		Name:        "portfolio",
+		Module:      "market",
+		Description: "Track the items you've bought and what they'd sell for now.",
+		Handler:     m.portfolioShow,
+		Subcommands: []*router.Command{
+			{
+				Name:        "add",
+				Description: "Record a purchase.",
+				Args: []router.Arg{
+					{Name: "quantity", Description: "How many you bought", Type: router.ArgInteger, Required: true, Min: 1},
+					{Name: "buy_price", Description: "What you paid for one, e.g. 12.50", Required: true},
+					itemArg,
+				},
+				Usage:   "Usage: /portfolio add <quantity> <buy_price> <item_name...>, e.g. /portfolio add 3 12.50 ak redline ft",
+				Handler: m.portfolioAdd,
+			},
+			{
+				Name:        "show",
+				Description: "Value your portfolio and show the profit or loss.",
+				Handler:     m.portfolioShow,
+			},
+			{
+				Name:        "remove",
+				Description: "Remove a purchase, e.g. once you've sold it.",
+				Args:        []router.Arg{{Name: "number", Description: "Number from /portfolio", Type: router.ArgInteger, Required: true, Min: 1}},
+				Handler:     m.portfolioRemove,
+			},
+			{
+				Name:        "export",
+				Description: "Download your portfolio as a CSV spreadsheet.",
+				Handler:     m.portfolioExport,
+			},
+		},
+	})
+	r.Register(router.Command{
+		Name:        "market",
+		Module:      "market",
+		Description: "Choose the game and currency your market lookups use.",
+		Subcommands: []*router.Command{
+			{
+				Name:        "defaults",
+				Description: "Show or set your default game and currency.",
+				Args:        []router.Arg{gameArg, currencyArg},
+				Handler:     m.setDefaults,
+			},
+			{
+				Name:        "reset",
+				Description: "Go back to the server's default game and currency.",
+				Handler:     m.resetDefaults,
+			},
+		},
+	})
+}
+
+// item resolves the item name a command was given. When it can't, it replies
+// with the closest matches and returns false.
+func (m *Market) item(req *router.Request, appID int, query string) (string, bool) {
+	name, suggestions, err := m.resolveItem(req.Context(), appID, query)
+	if err != nil {
+		req.Reply("Failed to look up item: " + err.Error())
+		return "", false
+	}
+	if name != "" {
+		return name, true
+	}
+	if len(suggestions) == 0 {
+		req.Reply("No " + GameName(appID) + " item matches `" + query + "`.")
+		return "", false
+	}
+	req.Reply("Couldn't pin down `" + query + "`. Did you mean:\n- " + strings.Join(suggestions, "\n- "))
+	return "", false
+}
+
+// remember adds real market names to the name index.
+func (m *Market) remember(appID int, itemNames ...string) {
+	if err := m.names.Add(appID, itemNames...); err != nil {
+		util.LoggerInit("STEAMMARKET", "remember").Error("Failed to save item names", "error", err)
+	}
+}
+
+func defaultKey(itemName string) Key {
+	return Key{AppID: DefaultAppID, Currency: Currencies[DefaultCurrencyCode].ID, MarketHashName: itemName}
+}
+
+func suggestGames(partial string) []string {
+	var out []string
+	for _, name := range GameNames() {
+		if strings.HasPrefix(name, strings.ToLower(partial)) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// splitOptions takes a trailing game name and currency code off a text
+// command's item name, e.g. "Arcana Bundle dota2 eur". Numeric app IDs only
+// work as slash command options, since plenty of item names end in a number.
+func splitOptions(itemName string) (item, game, currency string) {
+	words := strings.Fields(itemName)
+	for range 2 {
+		if len(words) < 2 {
+			break
+		}
+		last := words[len(words)-1]
+		if _, ok := Games[strings.ToLower(last)]; ok && game == "" {
+			game = last
+		} else if _, ok := Currencies[strings.ToUpper(last)]; ok && currency == "" {
+			currency = last
+		} else {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " "), game, currency
+}
+
+// splitDays takes a trailing day count off a text command's item name. Only
+// a number from 1 to maxHistoryDays counts, so names ending in a year keep it.
+func splitDays(itemName string) (item, days string) {
+	if i := strings.LastIndex(itemName, " "); i > 0 {
+		if n, err := strconv.Atoi(itemName[i+1:]); err == nil && n >= 1 && n <= maxHistoryDays {
+			return itemName[:i], itemName[i+1:]
+		}
+	}
+	return itemName, ""
+}
+
+func (m *Market) price(req *router.Request) {
+	itemName, game, currency := req.Arg(0), req.Arg(1), req.Arg(2)
+	if req.Interaction == nil {
+		itemName, game, currency = splitOptions(itemName)
+	}
+	appID, cur, err := m.resolve(req, game, currency)
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	itemName, ok := m.item(req, appID, itemName)
+	if !ok {
+		return
+	}
+	price, err := m.prices.Get(req.Context(), Key{AppID: appID, Currency: cur.ID, MarketHashName: itemName})
+	if errors.Is(err, ErrNoPrice) {
+		req.Reply("No price found for `" + itemName + "`. Check the name matches the market listing.")
+		return
+	}
+	if err != nil {
+		req.Reply("Error fetching price: " + err.Error())
+		return
+	}
+	m.remember(appID, itemName)
+	result := "**" + itemName + "**"
+	if appID != DefaultAppID {
+		result += " (" + GameName(appID) + ")"
+	}
+	result += "\nPrice - Low: " + cur.FormatPrice(price.PriceLow) + "\n" +
+		"Median: " + cur.FormatPrice(price.PriceMedian)
+	if price.Volume != "" {
+		result += "\nSold in the last 24h: " + price.Volume
+	}
+	if price.Stale {
+		result += "\n_Cached " + time.Since(price.Fetched).Round(time.Minute).String() + " ago, Steam is slow to answer right now._"
+	}
+	req.Reply(result)
+}
+
+func (m *Market) priceHistory(req *router.Request) {
+	itemName, daysArg, days := req.Arg(0), req.Arg(1), defaultHistoryDays
+	if req.Interaction == nil {
+		itemName, daysArg = splitDays(itemName)
+	}
+	if daysArg != "" {
+		n, err := strconv.Atoi(daysArg)
+		if err != nil || n < 1 || n > maxHistoryDays {
+			req.Reply(fmt.Sprintf("`%s` isn't a number of days. Use 1 to %d.", daysArg, maxHistoryDays))
+			return
+		}
+		days = n
+	}
+	appID, cur, err := m.resolve(req, "", "")
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	itemName, ok := m.item(req, appID, itemName)
+	if !ok {
+		return
+	}
+	key := Key{AppID: appID, Currency: cur.ID, MarketHashName: itemName}
+	if appID == DefaultAppID && slices.Contains(m.cfg.Watched, itemName) {
+		// The sampler prices watched items in the default currency, so that's
+		// where their history has no gaps.
+		key, cur = defaultKey(itemName), Currencies[DefaultCurrencyCode]
+	}
+	samples := m.history.Since(key, time.Now().AddDate(0, 0, -days))
+	if len(samples) == 0 {
+		req.Reply("No price history for `" + itemName + "` yet. Run /price on it, or ask an admin to add it to the watched items.")
+		return
+	}
+
+	var lowest, median []chart.Point
+	for _, s := range samples {
+		if s.Lowest > 0 {
+			lowest = append(lowest, chart.Point{Time: s.Time, Value: s.Lowest})
+		}
+		if s.Median > 0 {
+			median = append(median, chart.Point{Time: s.Time, Value: s.Median})
+		}
+	}
+	var png bytes.Buffer
+	err = chart.Line(&png, chartWidth, chartHeight, []chart.Series{
+		{Name: "Lowest", Color: color.RGBA{0x00, 0x99, 0x88, 0xff}, Points: lowest},
+		{Name: "Median", Color: color.RGBA{0xdd, 0x66, 0x00, 0xff}, Points: median},
+	})
+	if err != nil {
+		req.Reply("Failed to draw price chart: " + err.Error())
+		return
+	}
+	req.ReplyFiles(&discordgo.MessageEmbed{
+		Title:       itemName,
+		Description: fmt.Sprintf("%d samples over the last %d days, in %s.", len(samples), days, cur.Code),
+		Color:       0x00ffcc,
+		Image:       &discordgo.MessageEmbedImage{URL: "attachment://pricehistory.png"},
+	}, &discordgo.File{Name: "pricehistory.png", ContentType: "image/png", Reader: &png})
+}
+
+// RunSampler prices every watched item every SampleEvery, so their history
+// keeps growing between /price lookups. It returns when ctx is cancelled.
+func (m *Market) RunSampler(ctx context.Context) {
+	logger := util.LoggerInit("STEAMMARKET", "Sampler")
+	every := time.Duration(m.cfg.SampleEvery)
+	if every <= 0 || len(m.cfg.Watched) == 0 {
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		for _, itemName := range m.cfg.Watched {
+			if _, err := m.prices.Get(ctx, defaultKey(itemName)); err != nil && ctx.Err() == nil {
+				logger.Warn("Failed to sample watched item", "item", itemName, "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(sampleSpacing):
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Market) watchAdd(req *router.Request) {
+	direction, target, itemName := req.Arg(0), req.Arg(1), req.Arg(2)
+	if direction != "below" && direction != "above" {
+		req.Reply(req.Command.UsageText(req.Prefix()))
+		return
+	}
+	price, ok := ParsePrice(target)
+	if !ok || price <= 0 {
+		req.Reply("`" + target + "` isn't a price. Use a number like 12.50.")
+		return
+	}
+	appID, cur, err := m.resolve(req, "", "")
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	itemName, ok = m.item(req, appID, itemName)
+	if !ok {
+		return
+	}
+	watch := Watch{
+		Item:     itemName,
+		AppID:    appID,
+		Currency: cur.ID,
+		Above:    direction == "above",
+		Target:   price,
+	}
+	if req.GuildID != "" {
+		watch.ChannelID = req.ChannelID
+	}
+	if err := m.watches.Add(req.Author.ID, watch); err != nil {
+		req.Reply("Failed to add watch: " + err.Error())
+		return
+	}
+	req.Reply(fmt.Sprintf("Watching **%s**. I'll DM you when the lowest price goes %s %s.", itemName, direction, cur.Format(price)))
+}
+
+func (m *Market) watchList(req *router.Request) {
+	watches := m.watches.List(req.Author.ID)
+	if len(watches) == 0 {
+		req.Reply("You aren't watching anything. Add a watch with /watch add.")
+		return
+	}
+	lines := make([]string, len(watches))
+	for i, w := range watches {
+		lines[i] = fmt.Sprintf("`%d.` **%s** %s %s", i+1, w.Item, w.Direction(), CurrencyByID(w.Currency).Format(w.Target))
+		if w.AppID != DefaultAppID {
+			lines[i] += " (" + GameName(w.AppID) + ")"
+		}
+		if w.Triggered {
+			lines[i] += " (alerted)"
+		}
+	}
+	req.ReplyPages(router.Paginate("Your Watches", lines, maxWatches))
+}
+
+func (m *Market) watchRemove(req *router.Request) {
+	n, err := strconv.Atoi(req.Arg(0))
+	if err != nil {
+		req.Reply(req.Command.UsageText(req.Prefix()))
+		return
+	}
+	removed, err := m.watches.Remove(req.Author.ID, n)
+	if err != nil {
+		req.Reply("Failed to remove watch: " + err.Error())
+		return
+	}
+	req.Reply("Stopped watching **" + removed.Item + "**.")
+}
+
+func (m *Market) setDefaults(req *router.Request) {
+	game, currency := req.Arg(0), req.Arg(1)
+	d := m.prefs.Get(req.Author.ID)
+	if game != "" {
+		appID, err := ParseGame(game)
+		if err != nil {
+			req.Reply(err.Error())
+			return
+		}
+		d.AppID = appID
+	}
+	if currency != "" {
+		cur, err := LookupCurrency(currency)
+		if err != nil {
+			req.Reply(err.Error())
+			return
+		}
+		d.Currency = cur.Code
+	}
+	if game != "" || currency != "" {
+		if err := m.prefs.Set(req.Author.ID, d); err != nil {
+			req.Reply("Failed to save your defaults: " + err.Error())
+			return
+		}
+	}
+	appID, cur, err := m.resolve(req, "", "")
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	req.Reply("Your market lookups use " + GameName(appID) + " prices in " + cur.Code + ".")
+}
+
+func (m *Market) resetDefaults(req *router.Request) {
+	if err := m.prefs.Set(req.Author.ID, Defaults{}); err != nil {
+		req.Reply("Failed to reset your defaults: " + err.Error())
+		return
+	}
+	appID, cur, err := m.resolve(req, "", "")
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	req.Reply("Your market lookups are back to " + GameName(appID) + " prices in " + cur.Code + ".")
+}
+
+func (m *Market) inventory(req *router.Request) {
+	steamID, err := m.steam.resolveSteamID(req.Context(), req.Arg(0))
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	appID, cur, err := m.resolve(req, req.Arg(1), "")
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	holdings, err := m.steam.inventory(req.Context(), steamID, appID)
+	if err != nil {
+		req.Reply("Failed to fetch inventory: " + err.Error())
+		return
+	}
+	if len(holdings) == 0 {
+		req.Reply("That " + GameName(appID) + " inventory is empty.")
+		return
+	}
+	var marketable []string
+	for _, h := range holdings {
+		if h.Marketable {
+			marketable = append(marketable, h.Name)
+		}
+	}
+	m.remember(appID, marketable...)
+
+	ctx, cancel := context.WithTimeout(req.Context(), valuationTimeout)
+	m.value(ctx, holdings, appID, cur)
+	cancel()
+
+	var (
+		total                       float64
+		items, unmarketable         int
+		top, unpriced, failed, late []string
+	)
+	for _, h := range holdings {
+		items += h.Count
+		switch {
+		case !h.Marketable:
+			unmarketable += h.Count
+		case h.Late:
+			late = append(late, fmt.Sprintf("%dx %s", h.Count, h.Name))
+		case h.Failed:
+			failed = append(failed, fmt.Sprintf("%dx %s", h.Count, h.Name))
+		case !h.Priced:
+			unpriced = append(unpriced, fmt.Sprintf("%dx %s", h.Count, h.Name))
+		default:
+			total += h.Value()
+			if len(top) < topHoldings {
+				line := fmt.Sprintf("`%dx` **%s** %s", h.Count, h.Name, cur.Format(h.Value()))
+				if h.Count > 1 {
+					line += " (" + cur.Format(h.Price) + " each)"
+				}
+				top = append(top, line)
+			}
+		}
+	}
+	fields := []*discordgo.MessageEmbedField{{Name: "Most valuable", Value: fieldList(top)}}
+	if len(unpriced) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "No market price", Value: fieldList(unpriced)})
+	}
+	if len(failed) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Couldn't fetch", Value: fieldList(failed)})
+	}
+	if len(late) > 0 {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Not priced in time", Value: fieldList(late)})
+	}
+	req.ReplyEmbed(&discordgo.MessageEmbed{
+		Title:       GameName(appID) + " inventory of " + steamID,
+		URL:         "https://steamcommunity.com/profiles/" + steamID + "/inventory/",
+		Description: "Total value: **" + cur.Format(total) + "**",
+		Color:       0x00ffcc,
+		Fields:      fields,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d items, %d not marketable. Valued at the lowest listing price.", items, unmarketable),
+		},
+	})
+}
+
+func (m *Market) portfolioAdd(req *router.Request) {
+	quantity, buyPrice, itemName := req.Arg(0), req.Arg(1), req.Arg(2)
+	n, err := strconv.Atoi(quantity)
+	if err != nil || n < 1 {
+		req.Reply("`" + quantity + "` isn't a quantity. Use a whole number like 3.")
+		return
+	}
+	price, ok := ParsePrice(buyPrice)
+	if !ok || price < 0 {
+		req.Reply("`" + buyPrice + "` isn't a price. Use a number like 12.50.")
+		return
+	}
+	appID, cur, err := m.resolve(req, "", "")
+	if err != nil {
+		req.Reply(err.Error())
+		return
+	}
+	itemName, ok = m.item(req, appID, itemName)
+	if !ok {
+		return
+	}
+	lot := Lot{Item: itemName, AppID: appID, Currency: cur.ID, Quantity: n, BuyPrice: price, Added: time.Now()}
+	if err := m.folios.Add(req.Author.ID, lot); err != nil {
+		req.Reply("Failed to add to portfolio: " + err.Error())
+		return
+	}
+	req.Reply(fmt.Sprintf("Added %dx **%s** at %s each to your portfolio.", n, itemName, cur.Format(price)))
+}
+
+// holding is every lot of one item added together.
+type holding struct {
+	key                Key
+	quantity, unpriced int
+	cost, value        float64
+	// lots are the numbers /portfolio remove takes for this item's purchases.
+	lots []int
+}
+
+// numbers renders lot numbers for /portfolio remove, e.g. "#1, #4".
+func numbers(lots []int) string {
+	out := make([]string, len(lots))
+	for i, n := range lots {
+		out[i] = "#" + strconv.Itoa(n)
+	}
+	return strings.Join(out, ", ")
+}
+
+func (m *Market) portfolioShow(req *router.Request) {
+	lots := m.folios.List(req.Author.ID)
+	if len(lots) == 0 {
+		req.Reply("Your portfolio is empty. Add a purchase with /portfolio add.")
+		return
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), valuationTimeout)
+	positions := m.valueLots(ctx, lots)
+	cancel()
+
+	var order []Key
+	byItem := make(map[Key]*holding)
+	totals := make(map[int]*holding)
+	var currencies []int
+	for i, p := range positions {
+		h, ok := byItem[p.key()]
+		if !ok {
+			h = &holding{key: p.key()}
+			byItem[p.key()] = h
+			order = append(order, p.key())
+		}
+		h.lots = append(h.lots, i+1)
+		total, ok := totals[p.Currency]
+		if !ok {
+			total = &holding{}
+			totals[p.Currency] = total
+			currencies = append(currencies, p.Currency)
+		}
+		for _, sum := range []*holding{h, total} {
+			sum.quantity += p.Quantity
+			if p.Priced {
+				sum.cost += p.Cost()
+				sum.value += p.Value()
+			} else {
+				sum.unpriced += p.Quantity
+			}
+		}
+	}
+
+	var lines []string
+	for _, id := range currencies {
+		total, cur := totals[id], CurrencyByID(id)
+		line := fmt.Sprintf("**Total (%s):** %d priced, cost %s, worth %s after fees, %s", cur.Code, total.quantity-total.unpriced, cur.Format(total.cost), cur.Format(total.value), profitLoss(cur, total.cost, total.value))
+		if total.unpriced > 0 {
+			line += fmt.Sprintf(". %d without a market price left out", total.unpriced)
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "")
+	for _, key := range order {
+		h, cur := byItem[key], CurrencyByID(key.Currency)
+		line := fmt.Sprintf("%s `%dx` **%s** ", numbers(h.lots), h.quantity, key.MarketHashName)
+		if h.unpriced == h.quantity {
+			line += "no market price right now"
+		} else {
+			line += fmt.Sprintf("cost %s, worth %s, %s", cur.Format(h.cost), cur.Format(h.value), profitLoss(cur, h.cost, h.value))
+		}
+		lines = append(lines, line)
+	}
+	pages := router.Paginate("Your Portfolio", lines, 15)
+	for _, page := range pages {
+		page.Footer = &discordgo.MessageEmbedFooter{Text: "Valued at the lowest listing, less Steam's 15% fee. Remove a purchase by its # with /portfolio remove."}
+	}
+	req.ReplyPages(pages)
+}
+
+// profitLoss renders an unrealized gain or loss, e.g. "**+£6.54 (+21.8%)**".
+func profitLoss(cur Currency, cost, value float64) string {
+	diff := value - cost
+	sign := "+"
+	if diff < 0 {
+		sign = "-"
+	}
+	s := sign + cur.Format(math.Abs(diff))
+	if cost > 0 {
+		s += fmt.Sprintf(" (%s%.1f%%)", sign, math.Abs(diff)/cost*100)
+	}
+	return "**" + s + "**"
+}
+
+func (m *Market) portfolioRemove(req *router.Request) {
+	n, err := strconv.Atoi(req.Arg(0))
+	if err != nil {
+		req.Reply(req.Command.UsageText(req.Prefix()))
+		return
+	}
+	removed, err := m.folios.Remove(req.Author.ID, n)
+	if err != nil {
+		req.Reply("Failed to remove from portfolio: " + err.Error())
+		return
+	}
+	req.Reply(fmt.Sprintf("Removed %dx **%s** from your portfolio.", removed.Quantity, removed.Item))
+}
+
+func (m *Market) portfolioExport(req *router.Request) {
+	lots := m.folios.List(req.Author.ID)
+	if len(lots) == 0 {
+		req.Reply("Your portfolio is empty. Add a purchase with /portfolio add.")
+		return
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), valuationTimeout)
+	positions := m.valueLots(ctx, lots)
+	cancel()
+	var out bytes.Buffer
+	if err := writeCSV(&out, positions); err != nil {
+		req.Reply("Failed to export portfolio: " + err.Error())
+		return
+	}
+	req.ReplyFiles(&discordgo.MessageEmbed{
+		Title:       "Your Portfolio",
+		Description: fmt.Sprintf("%d purchases, valued at the lowest listing less Steam's fee. The number column is the # /portfolio shows and /portfolio remove takes.", len(lots)),
+		Color:       0x00ffcc,
+	}, &discordgo.File{Name: "portfolio.csv", ContentType: "text/csv", Reader: &out})
+}
+
+// fieldList joins lines for an embed field, cutting it off at Discord's
+// 1024 character limit.
+func fieldList(lines []string) string {
+	if len(lines) == 0 {
+		return "none"
+	}
+	const limit = 1024
+	var b strings.Builder
+	for i, line := range lines {
+		more := fmt.Sprintf("\n...and %d more", len(lines)-i)
+		if b.Len()+len(line)+1+len(more) > limit {
+			b.WriteString(more)
+			break
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package steammarket
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Games maps the short names /price accepts to Steam app IDs.
+var Games = map[string]int{
+	"cs2":   730,
+	"csgo":  730,
+	"dota2": 570,
+	"tf2":   440,
+	"rust":  252490,
+}
+
+var gameNames = map[int]string{
+	730:    "Counter-Strike 2",
+	570:    "Dota 2",
+	440:    "Team Fortress 2",
+	252490: "Rust",
+}
+
+// ParseGame turns a game short name or numeric app ID into an app ID.
+func ParseGame(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if appID, ok := Games[s]; ok {
+		return appID, nil
+	}
+	appID, err := strconv.Atoi(s)
+	if err != nil || appID <= 0 {
+		return 0, fmt.Errorf("unknown game %q, use %s or a numeric app ID", s, strings.Join(GameNames(), ", "))
+	}
+	return appID, nil
+}
+
+// GameNames lists the short names ParseGame accepts.
+func GameNames() []string {
+	names := make([]string, 0, len(Games))
+	for name := range Games {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GameName is the display name of appID, or "app <id>" for games we don't know.
+func GameName(appID int) string {
+	if name, ok := gameNames[appID]; ok {
+		return name
+	}
+	return "app " + strconv.Itoa(appID)
+}
+
+// Currency is a Steam wallet currency.
+type Currency struct {
+	Code string
+	// ID is Steam's number for the currency in market URLs.
+	ID     int
+	Symbol string
+	// Suffix puts the symbol after the amount, e.g. "12,50zł".
+	Suffix   bool
+	Decimals int
+}
+
+// Currencies lists the Steam currencies /price accepts, by code.
+var Currencies = map[string]Currency{
+	"USD": {Code: "USD", ID: 1, Symbol: "$", Decimals: 2},
+	"GBP": {Code: "GBP", ID: 2, Symbol: "£", Decimals: 2},
+	"EUR": {Code: "EUR", ID: 3, Symbol: "€", Suffix: true, Decimals: 2},
+	"CHF": {Code: "CHF", ID: 4, Symbol: "CHF ", Decimals: 2},
+	"RUB": {Code: "RUB", ID: 5, Symbol: " ₽", Suffix: true, Decimals: 2},
+	"PLN": {Code: "PLN", ID: 6, Symbol: "zł", Suffix: true, Decimals: 2},
+	"BRL": {Code: "BRL", ID: 7, Symbol: "R$ ", Decimals: 2},
+	"JPY": {Code: "JPY", ID: 8, Symbol: "¥ ", Decimals: 0},
+	"NOK": {Code: "NOK", ID: 9, Symbol: " kr", Suffix: true, Decimals: 2},
+	"SGD": {Code: "SGD", ID: 13, Symbol: "S$", Decimals: 2},
+	"KRW": {Code: "KRW", ID: 16, Symbol: "₩ ", Decimals: 0},
+	"TRY": {Code: "TRY", ID: 17, Symbol: " TL", Suffix: true, Decimals: 2},
+	"UAH": {Code: "UAH", ID: 18, Symbol: "₴", Suffix: true, Decimals: 2},
+	"MXN": {Code: "MXN", ID: 19, Symbol: "Mex$ ", Decimals: 2},
+	"CAD": {Code: "CAD", ID: 20, Symbol: "CDN$ ", Decimals: 2},
+	"AUD": {Code: "AUD", ID: 21, Symbol: "A$ ", Decimals: 2},
+	"NZD": {Code: "NZD", ID: 22, Symbol: "NZ$ ", Decimals: 2},
+	"CNY": {Code: "CNY", ID: 23, Symbol: "¥ ", Decimals: 2},
+	"INR": {Code: "INR", ID: 24, Symbol: "₹ ", Decimals: 2},
+	"ZAR": {Code: "ZAR", ID: 28, Symbol: "R ", Decimals: 2},
+	"HKD": {Code: "HKD", ID: 29, Symbol: "HK$ ", Decimals: 2},
+}
+
+// DefaultCurrencyCode is used when neither the user nor the server picked one.
+const DefaultCurrencyCode = "GBP"
+
+// LookupCurrency finds a currency by its code, case-insensitively.
+func LookupCurrency(code string) (Currency, error) {
+	c, ok := Currencies[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Currency{}, fmt.Errorf("unknown currency %q, use one of %s", code, strings.Join(CurrencyCodes(), ", "))
+	}
+	return c, nil
+}
+
+// CurrencyByID finds a currency by Steam's number for it, falling back to the default.
+func CurrencyByID(id int) Currency {
+	for _, c := range Currencies {
+		if c.ID == id {
+			return c
+		}
+	}
+	return Currencies[DefaultCurrencyCode]
+}
+
+// CurrencyCodes lists every supported currency code.
+func CurrencyCodes() []string {
+	codes := make([]string, 0, len(Currencies))
+	for code := range Currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Format renders amount with the currency's symbol, e.g. "£12.50" or "12.50€".
+func (c Currency) Format(amount float64) string {
+	n := strconv.FormatFloat(amount, 'f', c.Decimals, 64)
+	if c.Suffix {
+		return n + c.Symbol
+	}
+	return c.Symbol + n
+}
+
+// FormatPrice re-renders a price string from Steam in currency c, so the
+// symbol is right whatever encoding Steam used. Unparseable prices are
+// returned unchanged.
+func (c Currency) FormatPrice(steamPrice string) string {
+	amount, ok := ParsePrice(steamPrice)
+	if !ok {
+		return steamPrice
+	}
+	return c.Format(amount)
+}
@@ -0,0 +1,267 @@
+package steammarket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"discordBot/bot/ratelimit"
+	"discordBot/store"
+	"discordBot/util"
+)
+
+const (
+	historyFile = "pricehistory.jsonl"
+	// legacyHistoryFile is the single JSON document history used to be kept
+	// in. It's read once and folded into historyFile.
+	legacyHistoryFile = "pricehistory.json"
+	// historyRetention is how long samples are kept.
+	historyRetention = 365 * 24 * time.Hour
+	// compactAfter is how many expired samples the log can hold before it's
+	// rewritten without them, as long as they're also most of the log.
+	compactAfter = 10000
+)
+
+// Sample is one recorded price overview. Prices are in the key's currency.
+type Sample struct {
+	Time   time.Time `json:"t"`
+	Lowest float64   `json:"low,omitempty"`
+	Median float64   `json:"median,omitempty"`
+	Volume int       `json:"volume,omitempty"`
+}
+
+// historyEntry is one line of the history log.
+type historyEntry struct {
+	Key string `json:"k"`
+	Sample
+}
+
+// History is the local time series of every price fetched from Steam. Samples
+// are appended to a log, one JSON line each, and indexed by item in memory,
+// so recording one doesn't rewrite the others. The log is rewritten without
+// expired samples when it's opened and once they make up most of it.
+type History struct {
+	mu      sync.RWMutex
+	path    string
+	log     *os.File
+	series  map[string][]Sample
+	live    int
+	expired int
+}
+
+// OpenHistory loads pricehistory.jsonl from the data directory, folding in a
+// pricehistory.json left by older versions.
+func OpenHistory() (*History, error) {
+	h := &History{path: filepath.Join(store.Dir(), historyFile), series: make(map[string][]Sample)}
+	legacy, err := store.Open(legacyHistoryFile, make(map[string][]Sample))
+	if err != nil {
+		return nil, err
+	}
+	legacy.View(func(series *map[string][]Sample) {
+		for k, samples := range *series {
+			h.series[k] = append(h.series[k], samples...)
+		}
+	})
+	if err := h.load(); err != nil {
+		return nil, err
+	}
+	if err := h.compact(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(filepath.Join(store.Dir(), legacyHistoryFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return h, nil
+}
+
+// load reads the log into memory. A line that doesn't parse, such as one cut
+// short by a crash, is skipped.
+func (h *History) load() error {
+	f, err := os.Open(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", h.path, err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e historyEntry
+		if json.Unmarshal(scanner.Bytes(), &e) != nil || e.Key == "" {
+			util.LoggerInit("STEAMMARKET", "History").Warn("Skipping unreadable price sample", "file", h.path)
+			continue
+		}
+		h.series[e.Key] = append(h.series[e.Key], e.Sample)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", h.path, err)
+	}
+	return nil
+}
+
+// compact drops samples past the retention period and rewrites the log with
+// what's left, then reopens it for appending. h.mu must be held, or h not
+// shared yet.
+func (h *History) compact(now time.Time) error {
+	cutoff := now.Add(-historyRetention)
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	h.live, h.expired = 0, 0
+	for k, samples := range h.series {
+		slices.SortStableFunc(samples, func(a, b Sample) int { return a.Time.Compare(b.Time) })
+		i := 0
+		for i < len(samples) && samples[i].Time.Before(cutoff) {
+			i++
+		}
+		samples = samples[i:]
+		if len(samples) == 0 {
+			delete(h.series, k)
+			continue
+		}
+		h.series[k] = samples
+		h.live += len(samples)
+		for _, s := range samples {
+			if err := enc.Encode(historyEntry{Key: k, Sample: s}); err != nil {
+				return fmt.Errorf("failed to encode %s: %w", h.path, err)
+			}
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(h.path), err)
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if h.log != nil {
+		h.log.Close()
+		h.log = nil
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return err
+	}
+	log, err := os.OpenFile(h.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", h.path, err)
+	}
+	h.log = log
+	return nil
+}
+
+// Close closes the log.
+func (h *History) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.log == nil {
+		return nil
+	}
+	err := h.log.Close()
+	h.log = nil
+	return err
+}
+
+func historyKey(key Key) string {
+	return fmt.Sprintf("%d/%d/%s", key.AppID, key.Currency, key.MarketHashName)
+}
+
+// Record appends a sample for key, dropping the item's samples past the
+// retention period.
+func (h *History) Record(key Key, overview Overview, at time.Time) error {
+	sample := Sample{Time: at}
+	sample.Lowest, _ = ParsePrice(overview.PriceLow)
+	sample.Median, _ = ParsePrice(overview.PriceMedian)
+	sample.Volume, _ = strconv.Atoi(strings.ReplaceAll(overview.Volume, ",", ""))
+	if sample.Lowest == 0 && sample.Median == 0 {
+		return nil
+	}
+	k := historyKey(key)
+	line, err := json.Marshal(historyEntry{Key: k, Sample: sample})
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.log == nil {
+		return fmt.Errorf("%s is closed", h.path)
+	}
+	if _, err := h.log.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", h.path, err)
+	}
+	samples := append(h.series[k], sample)
+	cutoff := at.Add(-historyRetention)
+	i := 0
+	for i < len(samples) && samples[i].Time.Before(cutoff) {
+		i++
+	}
+	h.series[k] = samples[i:]
+	h.live += 1 - i
+	h.expired += i
+	if h.expired >= compactAfter && h.expired > h.live {
+		return h.compact(at)
+	}
+	return nil
+}
+
+// Since returns the samples for key recorded after since, oldest first.
+func (h *History) Since(key Key, since time.Time) []Sample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var out []Sample
+	for _, s := range h.series[historyKey(key)] {
+		if s.Time.After(since) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// recording wraps fetch so every overview Steam returns is saved to h.
+func (h *History) recording(fetch Fetcher, clock ratelimit.Clock) Fetcher {
+	logger := util.LoggerInit("STEAMMARKET", "History")
+	return func(ctx context.Context, key Key) (Overview, error) {
+		overview, err := fetch(ctx, key)
+		if err != nil {
+			return overview, err
+		}
+		if err := h.Record(key, overview, clock.Now()); err != nil {
+			logger.Error("Failed to record price sample", "item", key.MarketHashName, "error", err)
+		}
+		return overview, nil
+	}
+}
+
+// ParsePrice reads a Steam price string such as "£1,234.56", "1.234,56€" or
+// "$0.03" into a number. Whichever of ',' and '.' comes last, followed by one
+// or two digits, is taken as the decimal separator.
+func ParsePrice(s string) (float64, bool) {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' || r == '.' || r == ',' {
+			b.WriteRune(r)
+		}
+	}
+	digits := strings.Trim(b.String(), ".,")
+	if digits == "" {
+		return 0, false
+	}
+	decimal := strings.LastIndexAny(digits, ".,")
+	if decimal >= 0 && len(digits)-decimal-1 <= 2 {
+		whole := strings.NewReplacer(".", "", ",", "").Replace(digits[:decimal])
+		digits = whole + "." + digits[decimal+1:]
+	} else {
+		digits = strings.NewReplacer(".", "", ",", "").Replace(digits)
+	}
+	v, err := strconv.ParseFloat(digits, 64)
+	return v, err == nil
+}
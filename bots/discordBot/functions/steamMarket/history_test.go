@@ -0,0 +1,114 @@
+package steammarket
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryAppendsAndReloads(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	redline := Key{AppID: 730, Currency: 2, MarketHashName: "AK-47 | Redline (Field-Tested)"}
+	asiimov := Key{AppID: 730, Currency: 3, MarketHashName: "AWP | Asiimov (Field-Tested)"}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	h, err := OpenHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := h.Record(redline, Overview{PriceLow: "£12.50", PriceMedian: "£13.00", Volume: "1,204"}, start.Add(time.Duration(i)*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h.Record(asiimov, Overview{PriceLow: "80,10€"}, start); err != nil {
+		t.Fatal(err)
+	}
+	// An overview without prices isn't worth a sample.
+	if err := h.Record(asiimov, Overview{}, start.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	h.Close()
+
+	raw, err := os.ReadFile(filepath.Join(dir, historyFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(raw), "\n"); lines != 4 {
+		t.Errorf("log has %d lines, want one per sample, 4", lines)
+	}
+
+	h, err = OpenHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	got := h.Since(redline, start.Add(30*time.Minute))
+	if len(got) != 2 {
+		t.Fatalf("Since = %d samples, want the 2 after the first", len(got))
+	}
+	want := Sample{Time: start.Add(time.Hour), Lowest: 12.5, Median: 13, Volume: 1204}
+	if !got[0].Time.Equal(want.Time) || got[0].Lowest != want.Lowest || got[0].Median != want.Median || got[0].Volume != want.Volume {
+		t.Errorf("first sample = %+v, want %+v", got[0], want)
+	}
+	if got := h.Since(asiimov, start.Add(-time.Second)); len(got) != 1 || got[0].Lowest != 80.1 {
+		t.Errorf("other item's samples = %+v, want one at 80.10", got)
+	}
+}
+
+func TestHistoryFoldsInLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	key := Key{AppID: 730, Currency: 2, MarketHashName: "AK-47 | Redline (Field-Tested)"}
+	now := time.Now()
+	legacy, _ := json.Marshal(map[string][]Sample{historyKey(key): {
+		{Time: now.Add(-400 * 24 * time.Hour), Lowest: 1},
+		{Time: now.Add(-time.Hour), Lowest: 2},
+	}})
+	if err := os.WriteFile(filepath.Join(dir, legacyHistoryFile), legacy, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := OpenHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if _, err := os.Stat(filepath.Join(dir, legacyHistoryFile)); !os.IsNotExist(err) {
+		t.Errorf("legacy file still there after folding it in: %v", err)
+	}
+	got := h.Since(key, time.Time{})
+	if len(got) != 1 || got[0].Lowest != 2 {
+		t.Errorf("samples = %+v, want only the one inside the retention period", got)
+	}
+}
+
+func TestHistorySkipsTornLine(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DATA_DIR", dir)
+	key := Key{AppID: 730, Currency: 1, MarketHashName: "Glove Case"}
+	line, _ := json.Marshal(historyEntry{Key: historyKey(key), Sample: Sample{Time: time.Now(), Lowest: 3}})
+	log := string(line) + "\n" + `{"k":"730/1/Glove Case","t":"20`
+	if err := os.WriteFile(filepath.Join(dir, historyFile), []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := OpenHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	if got := h.Since(key, time.Time{}); len(got) != 1 {
+		t.Errorf("samples = %+v, want the one whole line", got)
+	}
+	if err := h.Record(key, Overview{PriceLow: "$4.00"}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Since(key, time.Time{}); len(got) != 2 {
+		t.Errorf("samples after recording = %d, want 2", len(got))
+	}
+}
@@ -0,0 +1,191 @@
+package steammarket
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// inventoryContext is the inventory context that holds tradable items in
+	// every game we know of.
+	inventoryContext  = "2"
+	inventoryPageSize = 2000
+	// maxInventoryPages stops runaway paging on enormous inventories.
+	maxInventoryPages = 5
+	// valuationTimeout bounds how long /inventory spends pricing items. Items
+	// not priced by then are listed as not priced in time.
+	valuationTimeout = 3 * time.Minute
+	// topHoldings is how many of the most valuable items the reply lists.
+	topHoldings = 10
+)
+
+var (
+	steamID64Pattern = regexp.MustCompile(`^7656\d{13}$`)
+	profilePattern   = regexp.MustCompile(`steamcommunity\.com/(profiles|id)/([^/?#]+)`)
+
+	errPrivateInventory = errors.New("that inventory is private or the profile doesn't exist")
+)
+
+// Holding is one kind of item in an inventory and how many of it there are.
+type Holding struct {
+	Name       string
+	Count      int
+	Marketable bool
+	// Price is the price of one, valid when Priced is set.
+	Price  float64
+	Priced bool
+	// Late is set when valuation ran out of time before pricing it.
+	Late bool
+	// Failed is set when Steam couldn't be asked for its price, as opposed
+	// to having none.
+	Failed bool
+}
+
+// Value is what all Count of the holding are worth.
+func (h Holding) Value() float64 {
+	return h.Price * float64(h.Count)
+}
+
+type inventoryResponse struct {
+	Assets []struct {
+		ClassID    string `json:"classid"`
+		InstanceID string `json:"instanceid"`
+		Amount     string `json:"amount"`
+	} `json:"assets"`
+	Descriptions []struct {
+		ClassID        string `json:"classid"`
+		InstanceID     string `json:"instanceid"`
+		MarketHashName string `json:"market_hash_name"`
+		Marketable     int    `json:"marketable"`
+	} `json:"descriptions"`
+	MoreItems   int    `json:"more_items"`
+	LastAssetID string `json:"last_assetid"`
+	Success     int    `json:"success"`
+}
+
+// resolveSteamID turns a SteamID64, a /profiles/ URL or a /id/ vanity URL
+// into a SteamID64.
+func (c *steamClient) resolveSteamID(ctx context.Context, input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if steamID64Pattern.MatchString(input) {
+		return input, nil
+	}
+	match := profilePattern.FindStringSubmatch(input)
+	if match == nil {
+		return "", fmt.Errorf("`%s` isn't a SteamID64 or a steamcommunity.com profile link", input)
+	}
+	if match[1] == "profiles" {
+		if !steamID64Pattern.MatchString(match[2]) {
+			return "", fmt.Errorf("`%s` isn't a valid SteamID64", match[2])
+		}
+		return match[2], nil
+	}
+	_, body, err := c.get(ctx, "/id/"+url.PathEscape(match[2]), url.Values{"xml": {"1"}})
+	if err != nil {
+		return "", err
+	}
+	var profile struct {
+		SteamID64 string `xml:"steamID64"`
+	}
+	if err := xml.Unmarshal(body, &profile); err != nil || profile.SteamID64 == "" {
+		return "", fmt.Errorf("couldn't find a Steam profile called `%s`", match[2])
+	}
+	return profile.SteamID64, nil
+}
+
+// inventory fetches every item in steamID's appID inventory, grouped by market name.
+func (c *steamClient) inventory(ctx context.Context, steamID string, appID int) ([]Holding, error) {
+	byName := make(map[string]*Holding)
+	var order []string
+	query := url.Values{"l": {"english"}, "count": {strconv.Itoa(inventoryPageSize)}}
+	for page := 0; page < maxInventoryPages; page++ {
+		status, body, err := c.get(ctx, "/inventory/"+steamID+"/"+strconv.Itoa(appID)+"/"+inventoryContext, query)
+		if err != nil {
+			return nil, err
+		}
+		if status == http.StatusForbidden || status == http.StatusNotFound {
+			return nil, errPrivateInventory
+		}
+		var inv inventoryResponse
+		if err := json.Unmarshal(body, &inv); err != nil {
+			return nil, fmt.Errorf("unexpected inventory response from Steam (HTTP %d)", status)
+		}
+		if inv.Success != 1 {
+			return nil, errPrivateInventory
+		}
+
+		type class struct{ classID, instanceID string }
+		descriptions := make(map[class]int, len(inv.Descriptions))
+		for i, d := range inv.Descriptions {
+			descriptions[class{d.ClassID, d.InstanceID}] = i
+		}
+		for _, a := range inv.Assets {
+			i, ok := descriptions[class{a.ClassID, a.InstanceID}]
+			if !ok {
+				continue
+			}
+			d := inv.Descriptions[i]
+			amount, err := strconv.Atoi(a.Amount)
+			if err != nil || amount < 1 {
+				amount = 1
+			}
+			h, ok := byName[d.MarketHashName]
+			if !ok {
+				h = &Holding{Name: d.MarketHashName, Marketable: d.Marketable == 1}
+				byName[d.MarketHashName] = h
+				order = append(order, d.MarketHashName)
+			}
+			h.Count += amount
+		}
+		if inv.MoreItems == 0 || inv.LastAssetID == "" {
+			break
+		}
+		query.Set("start_assetid", inv.LastAssetID)
+	}
+
+	holdings := make([]Holding, len(order))
+	for i, name := range order {
+		holdings[i] = *byName[name]
+	}
+	return holdings, nil
+}
+
+// value prices every marketable holding through the price cache, one at a
+// time so the Steam throttle spaces the calls out. Whatever isn't priced when
+// ctx ends is marked Late.
+func (m *Market) value(ctx context.Context, holdings []Holding, appID int, cur Currency) {
+	for i := range holdings {
+		h := &holdings[i]
+		if !h.Marketable {
+			continue
+		}
+		if ctx.Err() != nil {
+			h.Late = true
+			continue
+		}
+		price, err := m.prices.Get(ctx, Key{AppID: appID, Currency: cur.ID, MarketHashName: h.Name})
+		if err != nil {
+			h.Late = ctx.Err() != nil
+			h.Failed = !h.Late && !errors.Is(err, ErrNoPrice)
+			continue
+		}
+		amount, ok := ParsePrice(price.PriceLow)
+		if !ok {
+			amount, ok = ParsePrice(price.PriceMedian)
+		}
+		h.Price, h.Priced = amount, ok
+	}
+	sort.SliceStable(holdings, func(i, j int) bool {
+		return holdings[i].Value() > holdings[j].Value()
+	})
+}
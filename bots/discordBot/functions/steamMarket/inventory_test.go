@@ -0,0 +1,321 @@
+package steammarket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"discordBot/bot/ratelimit"
+	"discordBot/bot/router"
+	"discordBot/bot/router/routertest"
+)
+
+const (
+	publicProfile  = "76561198000000001"
+	privateProfile = "76561198000000002"
+	slowItem       = "Operation Breakout Weapon Case"
+	// brokenItem's price lookups fail with a server error.
+	brokenItem = "Falchion Case"
+)
+
+// redlines is what the stand-in market search finds for "redline".
+var redlines = []string{
+	"AK-47 | Redline (Field-Tested)",
+	"StatTrak™ AK-47 | Redline (Field-Tested)",
+	"AK-47 | Redline (Minimal Wear)",
+	"AK-47 | Redline (Well-Worn)",
+}
+
+// standIn serves canned inventory, profile, search and priceoverview
+// responses in place of steamcommunity.com, counting the searches. Price
+// lookups for slowItem hang until the test ends.
+type standIn struct {
+	*httptest.Server
+	searches atomic.Int32
+}
+
+func newStandIn(t *testing.T) *standIn {
+	s := &standIn{}
+	release := make(chan struct{})
+	prices := map[string]string{
+		"AK-47 | Redline (Field-Tested)": `{"success":true,"lowest_price":"£10.00","median_price":"£10.50"}`,
+		"Glove Case":                     `{"success":true,"median_price":"£0.50"}`,
+		"AWP | Asiimov (Field-Tested)":   `{"success":false}`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/inventory/"+publicProfile+"/730/2", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("start_assetid") == "" {
+			fmt.Fprint(w, `{"success":1,"more_items":1,"last_assetid":"4","assets":[
+				{"classid":"1","instanceid":"0","amount":"1"},
+				{"classid":"1","instanceid":"0","amount":"1"},
+				{"classid":"2","instanceid":"0","amount":"3"},
+				{"classid":"3","instanceid":"0","amount":"1"}],
+				"descriptions":[
+				{"classid":"1","instanceid":"0","market_hash_name":"AK-47 | Redline (Field-Tested)","marketable":1},
+				{"classid":"2","instanceid":"0","market_hash_name":"Glove Case","marketable":1},
+				{"classid":"3","instanceid":"0","market_hash_name":"Service Medal","marketable":0}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"success":1,"assets":[{"classid":"4","instanceid":"0","amount":"1"},{"classid":"5","instanceid":"0","amount":"2"}],
+			"descriptions":[{"classid":"4","instanceid":"0","market_hash_name":"AWP | Asiimov (Field-Tested)","marketable":1},
+			{"classid":"5","instanceid":"0","market_hash_name":"`+brokenItem+`","marketable":1}]}`)
+	})
+	mux.HandleFunc("/inventory/"+privateProfile+"/730/2", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "null")
+	})
+	mux.HandleFunc("/id/gaben", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?><profile><steamID64>`+publicProfile+`</steamID64></profile>`)
+	})
+	mux.HandleFunc("/id/nobody", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0"?><response><error>The specified profile could not be found.</error></response>`)
+	})
+	mux.HandleFunc("/market/priceoverview/", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("market_hash_name")
+		switch name {
+		case slowItem:
+			<-release
+			return
+		case brokenItem:
+			http.Error(w, "busy", http.StatusBadGateway)
+			return
+		}
+		body, ok := prices[name]
+		if !ok {
+			body = `{"success":false}`
+		}
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("/market/search/render/", func(w http.ResponseWriter, r *http.Request) {
+		s.searches.Add(1)
+		var results []string
+		if strings.Contains(strings.ToLower(r.URL.Query().Get("query")), "redline") {
+			results = redlines
+		}
+		fmt.Fprint(w, `{"success":true,"results":[`)
+		for i, name := range results {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"hash_name":%q}`, name)
+		}
+		fmt.Fprint(w, `]}`)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	t.Cleanup(func() { close(release) })
+	return s
+}
+
+func newTestMarket(t *testing.T) (*Market, *standIn) {
+	t.Setenv("DATA_DIR", t.TempDir())
+	srv := newStandIn(t)
+	m, err := New(Config{
+		CommunityURL: srv.URL,
+		SteamRate:    ratelimit.Limit{Every: ratelimit.Duration(time.Millisecond), Burst: 100},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.history.Close() })
+	return m, srv
+}
+
+func TestResolveSteamID(t *testing.T) {
+	m, _ := newTestMarket(t)
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: publicProfile, want: publicProfile},
+		{input: "https://steamcommunity.com/profiles/" + publicProfile + "/", want: publicProfile},
+		{input: "https://steamcommunity.com/id/gaben", want: publicProfile},
+		{input: "https://steamcommunity.com/id/nobody", wantErr: true},
+		{input: "https://steamcommunity.com/profiles/123", wantErr: true},
+		{input: "gaben", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := m.steam.resolveSteamID(context.Background(), tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveSteamID(%q) = %q, %v, want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestInventoryPagesAndGroups(t *testing.T) {
+	m, _ := newTestMarket(t)
+	holdings, err := m.steam.inventory(context.Background(), publicProfile, DefaultAppID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Holding{
+		{Name: "AK-47 | Redline (Field-Tested)", Count: 2, Marketable: true},
+		{Name: "Glove Case", Count: 3, Marketable: true},
+		{Name: "Service Medal", Count: 1},
+		{Name: "AWP | Asiimov (Field-Tested)", Count: 1, Marketable: true},
+		{Name: brokenItem, Count: 2, Marketable: true},
+	}
+	if !slices.Equal(holdings, want) {
+		t.Errorf("inventory = %+v, want %+v", holdings, want)
+	}
+
+	if _, err := m.steam.inventory(context.Background(), privateProfile, DefaultAppID); err != errPrivateInventory {
+		t.Errorf("private inventory error = %v, want %v", err, errPrivateInventory)
+	}
+}
+
+func TestValueMarksItemsCutOffAsLate(t *testing.T) {
+	m, _ := newTestMarket(t)
+	holdings := []Holding{
+		{Name: "AK-47 | Redline (Field-Tested)", Count: 2, Marketable: true},
+		{Name: "AWP | Asiimov (Field-Tested)", Count: 1, Marketable: true},
+		{Name: brokenItem, Count: 2, Marketable: true},
+		{Name: slowItem, Count: 1, Marketable: true},
+		{Name: "Glove Case", Count: 3, Marketable: true},
+		{Name: "Service Medal", Count: 1},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	m.value(ctx, holdings, DefaultAppID, Currencies[DefaultCurrencyCode])
+
+	want := []Holding{
+		{Name: "AK-47 | Redline (Field-Tested)", Count: 2, Marketable: true, Price: 10, Priced: true},
+		{Name: "AWP | Asiimov (Field-Tested)", Count: 1, Marketable: true},
+		{Name: brokenItem, Count: 2, Marketable: true, Failed: true},
+		{Name: slowItem, Count: 1, Marketable: true, Late: true},
+		{Name: "Glove Case", Count: 3, Marketable: true, Late: true},
+		{Name: "Service Medal", Count: 1},
+	}
+	if !slices.Equal(holdings, want) {
+		t.Errorf("valued holdings =\n%+v\nwant\n%+v", holdings, want)
+	}
+}
+
+func TestInventoryCommand(t *testing.T) {
+	m, _ := newTestMarket(t)
+	r := router.New()
+	m.Register(r)
+
+	sent, _ := routertest.Run(r, routertest.GuildMessage("/inventory "+publicProfile))
+	if len(sent) != 1 || len(sent[0].Embeds) != 1 {
+		t.Fatalf("/inventory sent %+v, want one embed", sent)
+	}
+	embed := sent[0].Embeds[0]
+	if want := "Total value: **£21.50**"; embed.Description != want {
+		t.Errorf("description = %q, want %q", embed.Description, want)
+	}
+	var fields []string
+	for _, f := range embed.Fields {
+		fields = append(fields, f.Name+": "+f.Value)
+	}
+	wantFields := []string{
+		"Most valuable: `2x` **AK-47 | Redline (Field-Tested)** £20.00 (£10.00 each)\n`3x` **Glove Case** £1.50 (£0.50 each)",
+		"No market price: 1x AWP | Asiimov (Field-Tested)",
+		"Couldn't fetch: 2x " + brokenItem,
+	}
+	if !slices.Equal(fields, wantFields) {
+		t.Errorf("fields =\n%q\nwant\n%q", fields, wantFields)
+	}
+	if want := "9 items, 1 not marketable. Valued at the lowest listing price."; embed.Footer.Text != want {
+		t.Errorf("footer = %q, want %q", embed.Footer.Text, want)
+	}
+
+	sent, _ = routertest.Run(r, routertest.GuildMessage("/inventory "+privateProfile))
+	want := "Failed to fetch inventory: that inventory is private or the profile doesn't exist"
+	if len(sent) != 1 || sent[0].Content != want {
+		t.Errorf("private /inventory sent %+v, want %q", sent, want)
+	}
+}
+
+func TestPriceCommand(t *testing.T) {
+	m, _ := newTestMarket(t)
+	r := router.New()
+	m.Register(r)
+
+	redline := "**AK-47 | Redline (Field-Tested)**\nPrice - Low: £10.00\nMedian: £10.50"
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{content: "/price AK-47 | Redline (Field-Tested)", want: redline},
+		{content: "/price ak redline ft", want: redline},
+		{content: "/price ak redline ft cs2 GBP", want: redline},
+		{content: "/price \"ak redline ft\" gbp cs2", want: redline},
+		{content: "/price ak redline ft dota2", want: strings.Replace(redline, "**\n", "** (Dota 2)\n", 1)},
+	}
+	for _, tt := range tests {
+		sent, _ := routertest.Run(r, routertest.GuildMessage(tt.content))
+		if len(sent) != 1 || sent[0].Content != tt.want {
+			t.Errorf("%s sent %+v, want %q", tt.content, sent, tt.want)
+		}
+	}
+}
+
+func TestSplitOptions(t *testing.T) {
+	tests := []struct {
+		in, item, game, currency string
+	}{
+		{in: "ak redline ft", item: "ak redline ft"},
+		{in: "Arcana Bundle dota2 eur", item: "Arcana Bundle", game: "dota2", currency: "eur"},
+		{in: "Arcana Bundle EUR dota2", item: "Arcana Bundle", game: "dota2", currency: "EUR"},
+		{in: "Sticker | Team Liquid | Katowice 2019", item: "Sticker | Team Liquid | Katowice 2019"},
+		{in: "cs2", item: "cs2"},
+	}
+	for _, tt := range tests {
+		item, game, currency := splitOptions(tt.in)
+		if item != tt.item || game != tt.game || currency != tt.currency {
+			t.Errorf("splitOptions(%q) = %q, %q, %q, want %q, %q, %q", tt.in, item, game, currency, tt.item, tt.game, tt.currency)
+		}
+	}
+	if item, days := splitDays("ak redline ft 90"); item != "ak redline ft" || days != "90" {
+		t.Errorf("splitDays = %q, %q, want the day count taken off", item, days)
+	}
+	if item, days := splitDays("Katowice 2019"); item != "Katowice 2019" || days != "" {
+		t.Errorf("splitDays = %q, %q, want a year left on the name", item, days)
+	}
+}
+
+func TestPortfolioShow(t *testing.T) {
+	m, _ := newTestMarket(t)
+	r := router.New()
+	m.Register(r)
+	author := routertest.GuildMessage("/portfolio").Author.ID
+	gbp := Currencies[DefaultCurrencyCode].ID
+	for _, lot := range []Lot{
+		{Item: "AK-47 | Redline (Field-Tested)", AppID: DefaultAppID, Currency: gbp, Quantity: 2, BuyPrice: 8},
+		{Item: "AWP | Asiimov (Field-Tested)", AppID: DefaultAppID, Currency: gbp, Quantity: 3, BuyPrice: 50},
+		{Item: "AK-47 | Redline (Field-Tested)", AppID: DefaultAppID, Currency: gbp, Quantity: 1, BuyPrice: 9},
+	} {
+		if err := m.folios.Add(author, lot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sent, _ := routertest.Run(r, routertest.GuildMessage("/portfolio"))
+	if len(sent) != 1 || len(sent[0].Embeds) != 1 {
+		t.Fatalf("/portfolio sent %+v, want one embed", sent)
+	}
+	got := strings.Split(sent[0].Embeds[0].Description, "\n")
+	// £10.00 less the fees is £8.70 each.
+	want := []string{
+		"**Total (GBP):** 3 priced, cost £25.00, worth £26.10 after fees, **+£1.10 (+4.4%)**. 3 without a market price left out",
+		"",
+		"#1, #3 `3x` **AK-47 | Redline (Field-Tested)** cost £25.00, worth £26.10, **+£1.10 (+4.4%)**",
+		"#2 `3x` **AWP | Asiimov (Field-Tested)** no market price right now",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("/portfolio =\n%q\nwant\n%q", got, want)
+	}
+
+	sent, _ = routertest.Run(r, routertest.GuildMessage("/portfolio remove 2"))
+	if want := "Removed 3x **AWP | Asiimov (Field-Tested)** from your portfolio."; len(sent) != 1 || sent[0].Content != want {
+		t.Errorf("/portfolio remove 2 sent %+v, want %q", sent, want)
+	}
+}
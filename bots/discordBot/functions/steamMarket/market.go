@@ -1,53 +1,41 @@
 package steammarket
 
 import (
-	"discordBot/util"
-	"encoding/json"
-	"io"
-	"net/http"
+	"errors"
+	"fmt"
+	"time"
 )
 
-var data steamMarketInjection
+// DefaultAppID is CS2.
+const DefaultAppID = 730
 
-type steamMarketInjection struct {
-	Success     bool   `json:"success"`
-	PriceLow    string `json:"lowest_price"`
-	Volume      string `json:"volume"`
-	PriceMedian string `json:"median_price"`
-}
+// ErrNoPrice means Steam has no price overview for the item.
+var ErrNoPrice = errors.New("steam has no price for this item")
 
-func SteamMarketPriceOverviewURL(itemName string) (string, error) {
-	logger := util.LoggerInit("UTIL", "SteamMarketPriceOverviewURL")
+// RateLimitedError is returned when Steam answered 429 Too Many Requests.
+type RateLimitedError struct {
+	// RetryAfter is how long Steam asked us to wait, or 0 if it didn't say.
+	RetryAfter time.Duration
+}
 
-	// Ensure item name is URL-escaped
-	itemHash := util.MarketHashName(itemName)
-	output, err := util.FormatForSteamMarketInjection(itemHash)
-	if err != nil && output == "" {
-		logger.Error("URL formatting failed", "error", err, "output", output)
-		return "URL formatting failed", err
-	}
-	response, err := http.Get(output)
-	if err != nil {
-		logger.Error("Failed to get request", "error", err, "url", output)
-		return "HTTP request failed", err
-	}
-	defer response.Body.Close()
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		logger.Error("Failed to read response body", "error", err)
-		return "Read body failed", err
-	}
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		logger.Error("Failed to parse Steam Web API JSON", "error", err, "body", string(body))
-		return "JSON parse failed", err
+func (e *RateLimitedError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("steam is rate limiting price lookups, try again in %s", e.RetryAfter.Round(time.Second))
 	}
-	if !data.Success {
-		logger.Error("Steam API did not return success", "body", string(body))
-		return "Steam API did not return success", nil
-	}
-	result := "Price - Low: " + data.PriceLow + "\n" +
-		"Median: " + data.PriceMedian
-	return result, nil
+	return "steam is rate limiting price lookups, try again later"
+}
+
+// Key identifies one price overview.
+type Key struct {
+	AppID          int
+	Currency       int
+	MarketHashName string
+}
 
+// Overview is Steam's price overview for one item.
+type Overview struct {
+	Success     bool   `json:"success"`
+	PriceLow    string `json:"lowest_price"`
+	Volume      string `json:"volume"`
+	PriceMedian string `json:"median_price"`
 }
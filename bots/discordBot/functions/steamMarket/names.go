@@ -0,0 +1,374 @@
+package steammarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"discordBot/store"
+	"discordBot/util"
+)
+
+const (
+	namesFile = "itemnames.json"
+	// searchCount is how many results one market search asks Steam for.
+	searchCount = 20
+	// maxSuggestions is how many close matches a lookup that didn't resolve offers.
+	maxSuggestions = 5
+)
+
+// wears maps the exterior abbreviations people type to the words Steam puts
+// in brackets after a skin's name.
+var wears = map[string]string{
+	"fn": "Factory New",
+	"mw": "Minimal Wear",
+	"ft": "Field-Tested",
+	"ww": "Well-Worn",
+	"bs": "Battle-Scarred",
+}
+
+// nameTokens splits a name into lower case words for matching. Hyphens and
+// apostrophes are dropped rather than split on, so "AK-47" is "ak47" and
+// "Field-Tested" is "fieldtested"; other punctuation like "|", "™" and the
+// brackets separates words.
+func nameTokens(s string) []string {
+	return strings.FieldsFunc(strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == '\'':
+			return -1
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s), func(r rune) bool { return r == ' ' })
+}
+
+// queryTokens is nameTokens with wear abbreviations expanded.
+func queryTokens(query string) []string {
+	var out []string
+	for _, t := range nameTokens(query) {
+		if wear, ok := wears[t]; ok {
+			out = append(out, nameTokens(wear)...)
+		} else {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// tokenMatches reports whether the query word q matches the name word n: in
+// full, as a prefix of two letters or more, or with one typo in words of four
+// letters or more. A single letter would match too much to mean anything.
+func tokenMatches(q, n string) bool {
+	if q == n || len(q) >= 2 && strings.HasPrefix(n, q) {
+		return true
+	}
+	return len(q) >= 4 && (editDistance(q, n) <= 1 || len(n) > len(q) && editDistance(q, n[:len(q)]) <= 1)
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// neighbouring letters it takes to turn a into b.
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// match is how well one item name fits a query.
+type match struct {
+	name    string
+	matched int
+	// extra is the name's words that no query word matched.
+	extra []string
+}
+
+func score(query []string, name string) match {
+	m := match{name: name}
+	words := nameTokens(name)
+	used := make([]bool, len(words))
+	for _, q := range query {
+		for i, w := range words {
+			if !used[i] && tokenMatches(q, w) {
+				used[i] = true
+				m.matched++
+				break
+			}
+		}
+	}
+	for i, w := range words {
+		if !used[i] {
+			m.extra = append(m.extra, w)
+		}
+	}
+	return m
+}
+
+// rank scores every name against query and returns those matching at least
+// one word, best first: most words matched, then fewest words left over.
+func rank(query []string, names []string) []match {
+	var out []match
+	for _, name := range names {
+		if m := score(query, name); m.matched > 0 {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].matched != out[j].matched {
+			return out[i].matched > out[j].matched
+		}
+		if len(out[i].extra) != len(out[j].extra) {
+			return len(out[i].extra) < len(out[j].extra)
+		}
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+// pick chooses the item a query means, if it's unambiguous. Every query word
+// has to match. When several names do, one is picked only if each of the
+// others is that name plus words the user didn't type, so "ak redline ft"
+// means the plain Redline rather than its StatTrak™ or Souvenir versions, but
+// "ak redline" could be any wear and isn't picked.
+func pick(query []string, ranked []match) (string, bool) {
+	var complete []match
+	for _, m := range ranked {
+		if m.matched == len(query) {
+			complete = append(complete, m)
+		}
+	}
+	if len(complete) == 0 {
+		return "", false
+	}
+	best := complete[0]
+	for _, other := range complete[1:] {
+		if len(other.extra) == len(best.extra) || !subset(best.extra, other.extra) {
+			return "", false
+		}
+	}
+	return best.name, true
+}
+
+func subset(small, big []string) bool {
+	have := make(map[string]int, len(big))
+	for _, w := range big {
+		have[w]++
+	}
+	for _, w := range small {
+		if have[w] == 0 {
+			return false
+		}
+		have[w]--
+	}
+	return true
+}
+
+// ItemNames is a local index of market names by app ID, built from items that
+// priced, inventories and market searches, so exact names resolve without
+// asking Steam and the autocomplete has something to offer.
+type ItemNames struct {
+	file *store.File[map[int][]string]
+}
+
+// OpenItemNames loads itemnames.json from the data directory.
+func OpenItemNames() (*ItemNames, error) {
+	file, err := store.Open(namesFile, make(map[int][]string))
+	if err != nil {
+		return nil, err
+	}
+	return &ItemNames{file: file}, nil
+}
+
+// Add records names as real market names for appID.
+func (n *ItemNames) Add(appID int, names ...string) error {
+	var fresh []string
+	n.file.View(func(index *map[int][]string) {
+		for _, name := range names {
+			if name != "" && !slices.Contains((*index)[appID], name) && !slices.Contains(fresh, name) {
+				fresh = append(fresh, name)
+			}
+		}
+	})
+	if len(fresh) == 0 {
+		return nil
+	}
+	return n.file.Update(func(index *map[int][]string) error {
+		for _, name := range fresh {
+			if !slices.Contains((*index)[appID], name) {
+				(*index)[appID] = append((*index)[appID], name)
+			}
+		}
+		return nil
+	})
+}
+
+// Names lists appID's known market names, or every app's when appID is 0.
+func (n *ItemNames) Names(appID int) []string {
+	var out []string
+	n.file.View(func(index *map[int][]string) {
+		if appID != 0 {
+			out = append(out, (*index)[appID]...)
+			return
+		}
+		for _, names := range *index {
+			out = append(out, names...)
+		}
+	})
+	return out
+}
+
+// lookup finds query in names, ignoring case.
+func lookup(names []string, query string) (string, bool) {
+	for _, name := range names {
+		if strings.EqualFold(name, query) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+type searchResponse struct {
+	Success bool `json:"success"`
+	Results []struct {
+		HashName string `json:"hash_name"`
+	} `json:"results"`
+}
+
+// search asks the Steam Market search for appID items matching query and
+// returns their market names.
+func (c *steamClient) search(ctx context.Context, appID int, query string) ([]string, error) {
+	logger := util.LoggerInit("STEAMMARKET", "search")
+	status, body, err := c.get(ctx, "/market/search/render/", url.Values{
+		"query":               {query},
+		"appid":               {strconv.Itoa(appID)},
+		"norender":            {"1"},
+		"count":               {strconv.Itoa(searchCount)},
+		"search_descriptions": {"0"},
+		"sort_column":         {"popular"},
+		"sort_dir":            {"desc"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var results searchResponse
+	if err := json.Unmarshal(body, &results); err != nil || !results.Success {
+		logger.Error("Failed to parse Steam market search", "error", err, "status", status)
+		return nil, fmt.Errorf("unexpected search response from Steam (HTTP %d)", status)
+	}
+	names := make([]string, 0, len(results.Results))
+	for _, r := range results.Results {
+		names = append(names, r.HashName)
+	}
+	return names, nil
+}
+
+// searchQuery is what to send the market search for query. Steam's search
+// doesn't know the wear abbreviations, so they're left out and the results
+// are ranked locally instead.
+func searchQuery(query string) string {
+	var words []string
+	for _, w := range strings.Fields(query) {
+		if _, ok := wears[strings.ToLower(w)]; !ok {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// resolveItem turns what the user typed into an exact market name for appID.
+// Only an exact name resolves straight from the local index: it holds just
+// the items the bot has seen, so a query only one of them fits could still fit
+// others on Steam. Anything else is searched for on Steam first, and if it's
+// still ambiguous the closest matches come back as suggestions instead.
+func (m *Market) resolveItem(ctx context.Context, appID int, query string) (string, []string, error) {
+	logger := util.LoggerInit("STEAMMARKET", "resolveItem")
+	query = strings.TrimSpace(query)
+	tokens := queryTokens(query)
+	if len(tokens) == 0 {
+		return "", nil, fmt.Errorf("`%s` isn't an item name", query)
+	}
+	names := m.names.Names(appID)
+	if name, ok := lookup(names, query); ok {
+		return name, nil, nil
+	}
+
+	if err := m.throttle(ctx); err != nil {
+		return "", nil, err
+	}
+	found, err := m.steam.search(ctx, appID, searchQuery(query))
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", nil, err
+		}
+		// Without the search, the best bet is that the user typed the exact name.
+		logger.Warn("Market search failed", "query", query, "error", err)
+		return query, nil, nil
+	}
+	if err := m.names.Add(appID, found...); err != nil {
+		logger.Error("Failed to save item names", "error", err)
+	}
+	names = m.names.Names(appID)
+	if name, ok := lookup(names, query); ok {
+		return name, nil, nil
+	}
+	ranked := rank(tokens, names)
+	if name, ok := pick(tokens, ranked); ok {
+		return name, nil, nil
+	}
+	suggestions := make([]string, 0, maxSuggestions)
+	for _, r := range ranked {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, r.name)
+	}
+	return "", suggestions, nil
+}
+
+// suggestItems offers known item names for the item_name autocomplete, best
+// matches first. Until there's more than a letter to go on, it offers the
+// names that start with it.
+func (m *Market) suggestItems(partial string) []string {
+	names := m.names.Names(0)
+	tokens := queryTokens(partial)
+	if len(tokens) == 0 || len(partial) == 1 {
+		var out []string
+		for _, name := range names {
+			if strings.HasPrefix(strings.ToLower(name), strings.ToLower(partial)) {
+				out = append(out, name)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	var out []string
+	for _, r := range rank(tokens, names) {
+		if r.matched == len(tokens) {
+			out = append(out, r.name)
+		}
+	}
+	return out
+}
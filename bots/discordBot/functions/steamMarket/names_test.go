@@ -0,0 +1,81 @@
+package steammarket
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestTokenMatches(t *testing.T) {
+	tests := []struct {
+		q, n string
+		want bool
+	}{
+		{q: "redline", n: "redline", want: true},
+		{q: "ak", n: "ak47", want: true},
+		{q: "a", n: "ak47", want: false},
+		{q: "a", n: "a", want: true},
+		{q: "redlnie", n: "redline", want: true},
+		{q: "asimov", n: "asiimov", want: true},
+		{q: "red", n: "blue", want: false},
+	}
+	for _, tt := range tests {
+		if got := tokenMatches(tt.q, tt.n); got != tt.want {
+			t.Errorf("tokenMatches(%q, %q) = %v, want %v", tt.q, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestResolveItem(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		want     string
+		suggests []string
+		searched bool
+	}{
+		{name: "exact name from the index", query: "ak-47 | redline (field-tested)", want: "AK-47 | Redline (Field-Tested)"},
+		{name: "close match", query: "ak redline ft", want: "AK-47 | Redline (Field-Tested)", searched: true},
+		{name: "says StatTrak", query: "stattrak ak redline ft", want: "StatTrak™ AK-47 | Redline (Field-Tested)", searched: true},
+		{
+			name: "ambiguous on Steam though not in the index", query: "ak redline", searched: true,
+			suggests: []string{"AK-47 | Redline (Field-Tested)", "AK-47 | Redline (Well-Worn)", "AK-47 | Redline (Minimal Wear)", "StatTrak™ AK-47 | Redline (Field-Tested)"},
+		},
+		{name: "single letter", query: "a", searched: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, srv := newTestMarket(t)
+			m.remember(DefaultAppID, "AK-47 | Redline (Field-Tested)")
+
+			got, suggests, err := m.resolveItem(context.Background(), DefaultAppID, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || !slices.Equal(suggests, tt.suggests) {
+				t.Errorf("resolveItem(%q) = %q, %q, want %q, %q", tt.query, got, suggests, tt.want, tt.suggests)
+			}
+			if searched := srv.searches.Load() > 0; searched != tt.searched {
+				t.Errorf("searched Steam = %v, want %v", searched, tt.searched)
+			}
+		})
+	}
+}
+
+func TestSuggestItems(t *testing.T) {
+	m, _ := newTestMarket(t)
+	m.remember(DefaultAppID, "AWP | Asiimov (Field-Tested)", "AK-47 | Redline (Field-Tested)", "Glove Case")
+	tests := []struct {
+		partial string
+		want    []string
+	}{
+		{partial: "", want: []string{"AK-47 | Redline (Field-Tested)", "AWP | Asiimov (Field-Tested)", "Glove Case"}},
+		{partial: "a", want: []string{"AK-47 | Redline (Field-Tested)", "AWP | Asiimov (Field-Tested)"}},
+		{partial: "redl", want: []string{"AK-47 | Redline (Field-Tested)"}},
+	}
+	for _, tt := range tests {
+		if got := m.suggestItems(tt.partial); !slices.Equal(got, tt.want) {
+			t.Errorf("suggestItems(%q) = %q, want %q", tt.partial, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package steammarket
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"discordBot/store"
+)
+
+const (
+	portfolioFile = "portfolios.json"
+	// maxLots is how many purchases one user's portfolio can hold.
+	maxLots = 100
+	// Steam takes a 5% Steam fee and a game fee, 10% for the games we know
+	// of, from every sale. Each is at least one unit of the currency's
+	// smallest denomination.
+	steamFee = 0.05
+	gameFee  = 0.10
+)
+
+// Lot is one purchase in a user's portfolio.
+type Lot struct {
+	Item     string `json:"item"`
+	AppID    int    `json:"appid"`
+	Currency int    `json:"currency"`
+	Quantity int    `json:"quantity"`
+	// BuyPrice is what one cost.
+	BuyPrice float64   `json:"buy_price"`
+	Added    time.Time `json:"added"`
+}
+
+func (l Lot) key() Key {
+	return Key{AppID: l.AppID, Currency: l.Currency, MarketHashName: l.Item}
+}
+
+// Cost is what the whole lot cost.
+func (l Lot) Cost() float64 {
+	return l.BuyPrice * float64(l.Quantity)
+}
+
+// Portfolios stores every user's lots by user ID.
+type Portfolios struct {
+	file *store.File[map[string][]Lot]
+}
+
+// OpenPortfolios loads portfolios.json from the data directory.
+func OpenPortfolios() (*Portfolios, error) {
+	file, err := store.Open(portfolioFile, make(map[string][]Lot))
+	if err != nil {
+		return nil, err
+	}
+	return &Portfolios{file: file}, nil
+}
+
+// Add saves a purchase to userID's portfolio.
+func (p *Portfolios) Add(userID string, lot Lot) error {
+	return p.file.Update(func(lots *map[string][]Lot) error {
+		if len((*lots)[userID]) >= maxLots {
+			return fmt.Errorf("your portfolio already has %d entries, remove one first", maxLots)
+		}
+		(*lots)[userID] = append((*lots)[userID], lot)
+		return nil
+	})
+}
+
+// List returns userID's lots in the order they were added.
+func (p *Portfolios) List(userID string) []Lot {
+	var out []Lot
+	p.file.View(func(lots *map[string][]Lot) {
+		out = append(out, (*lots)[userID]...)
+	})
+	return out
+}
+
+// Remove deletes userID's n'th lot, counting from 1 as /portfolio and
+// /portfolio export number them.
+func (p *Portfolios) Remove(userID string, n int) (Lot, error) {
+	var removed Lot
+	err := p.file.Update(func(lots *map[string][]Lot) error {
+		mine := (*lots)[userID]
+		if n < 1 || n > len(mine) {
+			return fmt.Errorf("you have no portfolio entry number %d", n)
+		}
+		removed = mine[n-1]
+		mine = append(mine[:n-1], mine[n:]...)
+		if len(mine) == 0 {
+			delete(*lots, userID)
+		} else {
+			(*lots)[userID] = mine
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// afterFees is what the seller receives when an item sells for price in cur,
+// once Steam has taken its fees.
+func afterFees(price float64, cur Currency) float64 {
+	scale := math.Pow10(cur.Decimals)
+	paid := int64(math.Round(price * scale))
+	fees := func(received int64) int64 {
+		return max(1, int64(float64(received)*steamFee)) + max(1, int64(float64(received)*gameFee))
+	}
+	received := int64(float64(paid) / (1 + steamFee + gameFee))
+	for received+1+fees(received+1) <= paid {
+		received++
+	}
+	for received > 0 && received+fees(received) > paid {
+		received--
+	}
+	return float64(received) / scale
+}
+
+// Position is a lot valued at the current market price.
+type Position struct {
+	Lot
+	// Price is the lowest listing for one, valid when Priced is set.
+	Price  float64
+	Priced bool
+}
+
+// Net is what one would sell for after Steam's fees.
+func (p Position) Net() float64 {
+	return afterFees(p.Price, CurrencyByID(p.Currency))
+}
+
+// Value is what the whole lot would sell for after fees.
+func (p Position) Value() float64 {
+	return p.Net() * float64(p.Quantity)
+}
+
+// ProfitLoss is the unrealized profit, or loss when negative, on the lot.
+func (p Position) ProfitLoss() float64 {
+	return p.Value() - p.Cost()
+}
+
+// valueLots prices each distinct item in lots through the price cache, one
+// at a time so the Steam throttle spaces the calls out. Lots not priced when
+// ctx ends stay unpriced.
+func (m *Market) valueLots(ctx context.Context, lots []Lot) []Position {
+	prices := make(map[Key]float64)
+	positions := make([]Position, len(lots))
+	for i, lot := range lots {
+		positions[i].Lot = lot
+		key := lot.key()
+		if _, done := prices[key]; !done && ctx.Err() == nil {
+			prices[key] = -1
+			if price, err := m.prices.Get(ctx, key); err == nil {
+				if amount, ok := ParsePrice(price.PriceLow); ok {
+					prices[key] = amount
+				} else if amount, ok := ParsePrice(price.PriceMedian); ok {
+					prices[key] = amount
+				}
+			}
+		}
+		if price, ok := prices[key]; ok && price >= 0 {
+			positions[i].Price, positions[i].Priced = price, true
+		}
+	}
+	return positions
+}
+
+// writeCSV writes positions as a spreadsheet, one row per lot.
+func writeCSV(w io.Writer, positions []Position) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"number", "item", "game", "currency", "quantity", "buy_price", "cost", "current_price", "after_fees", "value", "profit_loss", "added"})
+	for i, p := range positions {
+		cur := CurrencyByID(p.Currency)
+		amount := func(v float64) string { return strconv.FormatFloat(v, 'f', cur.Decimals, 64) }
+		row := []string{
+			strconv.Itoa(i + 1), p.Item, GameName(p.AppID), cur.Code, strconv.Itoa(p.Quantity),
+			amount(p.BuyPrice), amount(p.Cost()), "", "", "", "", p.Added.UTC().Format(time.RFC3339),
+		}
+		if p.Priced {
+			row[7], row[8], row[9], row[10] = amount(p.Price), amount(p.Net()), amount(p.Value()), amount(p.ProfitLoss())
+		}
+		out.Write(row)
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,89 @@
+package steammarket
+
+import (
+	"discordBot/bot/router"
+	"discordBot/store"
+)
+
+const prefsFile = "marketprefs.json"
+
+// Defaults is a user's or server's preferred game and currency for market
+// lookups. Zero values mean no preference.
+type Defaults struct {
+	AppID    int    `json:"appid,omitempty"`
+	Currency string `json:"currency,omitempty"`
+}
+
+// Preferences stores each user's market Defaults by user ID.
+type Preferences struct {
+	file *store.File[map[string]Defaults]
+}
+
+// OpenPreferences loads marketprefs.json from the data directory.
+func OpenPreferences() (*Preferences, error) {
+	file, err := store.Open(prefsFile, make(map[string]Defaults))
+	if err != nil {
+		return nil, err
+	}
+	return &Preferences{file: file}, nil
+}
+
+// Get returns userID's defaults.
+func (p *Preferences) Get(userID string) Defaults {
+	var d Defaults
+	p.file.View(func(prefs *map[string]Defaults) {
+		d = (*prefs)[userID]
+	})
+	return d
+}
+
+// Set replaces userID's defaults. Empty defaults are removed.
+func (p *Preferences) Set(userID string, d Defaults) error {
+	return p.file.Update(func(prefs *map[string]Defaults) error {
+		if d == (Defaults{}) {
+			delete(*prefs, userID)
+		} else {
+			(*prefs)[userID] = d
+		}
+		return nil
+	})
+}
+
+// resolve picks the game and currency for a lookup: the ones given in the
+// command, then the user's defaults, then the server's, then CS2 in GBP.
+func (m *Market) resolve(req *router.Request, game, currency string) (int, Currency, error) {
+	user := m.prefs.Get(req.Author.ID)
+	var guild Defaults
+	if req.GuildID != "" && m.guildDefaults != nil {
+		guild = m.guildDefaults(req.GuildID)
+	}
+
+	appID := DefaultAppID
+	switch {
+	case game != "":
+		id, err := ParseGame(game)
+		if err != nil {
+			return 0, Currency{}, err
+		}
+		appID = id
+	case user.AppID != 0:
+		appID = user.AppID
+	case guild.AppID != 0:
+		appID = guild.AppID
+	}
+
+	code := DefaultCurrencyCode
+	switch {
+	case currency != "":
+		code = currency
+	case user.Currency != "":
+		code = user.Currency
+	case guild.Currency != "":
+		code = guild.Currency
+	}
+	cur, err := LookupCurrency(code)
+	if err != nil {
+		return 0, Currency{}, err
+	}
+	return appID, cur, nil
+}
@@ -0,0 +1,103 @@
+package steammarket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"discordBot/bot/ratelimit"
+	"discordBot/util"
+)
+
+// DefaultCommunityURL is where the market and inventory endpoints live.
+const DefaultCommunityURL = "https://steamcommunity.com"
+
+// steamClient talks to the Steam Community endpoints under baseURL, which
+// can point at a local stand-in server instead of Steam.
+type steamClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newSteamClient(baseURL string) *steamClient {
+	if baseURL == "" {
+		baseURL = DefaultCommunityURL
+	}
+	return &steamClient{baseURL: strings.TrimSuffix(baseURL, "/"), http: &http.Client{Timeout: fetchTimeout}}
+}
+
+// get fetches path and returns the body. A 429 becomes a *RateLimitedError.
+func (c *steamClient) get(ctx context.Context, path string, query url.Values) (int, []byte, error) {
+	logger := util.LoggerInit("STEAMMARKET", "steamClient")
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		logger.Error("Failed to get request", "error", err, "url", target)
+		return 0, nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusTooManyRequests {
+		retry, _ := strconv.Atoi(response.Header.Get("Retry-After"))
+		return response.StatusCode, nil, &RateLimitedError{RetryAfter: time.Duration(retry) * time.Second}
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		logger.Error("Failed to read response body", "error", err)
+		return response.StatusCode, nil, err
+	}
+	return response.StatusCode, body, nil
+}
+
+// overview asks Steam for the price overview of key.
+func (c *steamClient) overview(ctx context.Context, key Key) (Overview, error) {
+	logger := util.LoggerInit("STEAMMARKET", "overview")
+	query := url.Values{}
+	query.Set("appid", strconv.Itoa(key.AppID))
+	query.Set("currency", strconv.Itoa(key.Currency))
+	query.Set("market_hash_name", key.MarketHashName)
+	status, body, err := c.get(ctx, "/market/priceoverview/", query)
+	if err != nil {
+		return Overview{}, err
+	}
+	var overview Overview
+	if err := json.Unmarshal(body, &overview); err != nil {
+		logger.Error("Failed to parse Steam Web API JSON", "error", err, "status", status, "body", string(body))
+		return Overview{}, fmt.Errorf("unexpected response from Steam (HTTP %d)", status)
+	}
+	if !overview.Success {
+		return Overview{}, ErrNoPrice
+	}
+	return overview, nil
+}
+
+// throttle returns a function that waits for a token from limit. Every
+// market request the bot makes waits on it, keeping them within Steam's limits.
+func throttle(limiter *ratelimit.Limiter, limit ratelimit.Limit) func(context.Context) error {
+	key := ratelimit.Key{Name: "steam:market", Limit: limit}
+	return func(ctx context.Context) error {
+		return limiter.Wait(ctx, key)
+	}
+}
+
+// throttled wraps fetch so upstream calls wait on throttle first.
+func throttled(fetch Fetcher, throttle func(context.Context) error) Fetcher {
+	return func(ctx context.Context, k Key) (Overview, error) {
+		if err := throttle(ctx); err != nil {
+			return Overview{}, err
+		}
+		return fetch(ctx, k)
+	}
+}
@@ -0,0 +1,223 @@
+package steammarket
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"discordBot/bot/router"
+	"discordBot/store"
+	"discordBot/util"
+)
+
+const (
+	watchFile = "watches.json"
+	// maxWatches is how many watches one user can have.
+	maxWatches = 25
+	// rearmMargin is how far, as a fraction of the target, the price has to
+	// move back before a triggered watch can alert again.
+	rearmMargin = 0.05
+)
+
+// Watch is one user's price alert on one item.
+type Watch struct {
+	Item     string `json:"item"`
+	AppID    int    `json:"appid"`
+	Currency int    `json:"currency"`
+	// Above alerts when the price rises to Target; otherwise when it falls to it.
+	Above  bool    `json:"above,omitempty"`
+	Target float64 `json:"target"`
+	// ChannelID is where the watch was set up. The alert is posted there if
+	// the user can't be DMed.
+	ChannelID string `json:"channel_id,omitempty"`
+	// Triggered is set once the watch has alerted, until the price moves back.
+	Triggered bool `json:"triggered,omitempty"`
+}
+
+func (w Watch) key() Key {
+	return Key{AppID: w.AppID, Currency: w.Currency, MarketHashName: w.Item}
+}
+
+// Direction is "above" or "below".
+func (w Watch) Direction() string {
+	if w.Above {
+		return "above"
+	}
+	return "below"
+}
+
+// check reports whether price should alert. A watch alerts once when the
+// price crosses its target, then stays quiet until the price has moved back
+// past the target by rearmMargin.
+func (w *Watch) check(price float64) bool {
+	if w.Triggered {
+		if w.Above && price < w.Target*(1-rearmMargin) || !w.Above && price > w.Target*(1+rearmMargin) {
+			w.Triggered = false
+		}
+		return false
+	}
+	if w.Above && price >= w.Target || !w.Above && price <= w.Target {
+		w.Triggered = true
+		return true
+	}
+	return false
+}
+
+// Watchlists stores every user's watches by user ID.
+type Watchlists struct {
+	file *store.File[map[string][]*Watch]
+}
+
+// OpenWatchlists loads watches.json from the data directory.
+func OpenWatchlists() (*Watchlists, error) {
+	file, err := store.Open(watchFile, make(map[string][]*Watch))
+	if err != nil {
+		return nil, err
+	}
+	return &Watchlists{file: file}, nil
+}
+
+// Add saves a new watch for userID.
+func (l *Watchlists) Add(userID string, w Watch) error {
+	return l.file.Update(func(lists *map[string][]*Watch) error {
+		if len((*lists)[userID]) >= maxWatches {
+			return fmt.Errorf("you already have %d watches, remove one first", maxWatches)
+		}
+		(*lists)[userID] = append((*lists)[userID], &w)
+		return nil
+	})
+}
+
+// List returns userID's watches in the order they were added.
+func (l *Watchlists) List(userID string) []Watch {
+	var out []Watch
+	l.file.View(func(lists *map[string][]*Watch) {
+		for _, w := range (*lists)[userID] {
+			out = append(out, *w)
+		}
+	})
+	return out
+}
+
+// Remove deletes userID's n'th watch, counting from 1 as /watch list shows them.
+func (l *Watchlists) Remove(userID string, n int) (Watch, error) {
+	var removed Watch
+	err := l.file.Update(func(lists *map[string][]*Watch) error {
+		watches := (*lists)[userID]
+		if n < 1 || n > len(watches) {
+			return fmt.Errorf("you have no watch number %d", n)
+		}
+		removed = *watches[n-1]
+		watches = append(watches[:n-1], watches[n:]...)
+		if len(watches) == 0 {
+			delete(*lists, userID)
+		} else {
+			(*lists)[userID] = watches
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// keys lists each distinct item that anyone is watching.
+func (l *Watchlists) keys() []Key {
+	seen := make(map[Key]bool)
+	var out []Key
+	l.file.View(func(lists *map[string][]*Watch) {
+		for _, watches := range *lists {
+			for _, w := range watches {
+				if k := w.key(); !seen[k] {
+					seen[k] = true
+					out = append(out, k)
+				}
+			}
+		}
+	})
+	return out
+}
+
+// alert is a watch that just crossed its target.
+type alert struct {
+	userID string
+	watch  Watch
+	price  string
+}
+
+// checkPrice runs every watch on key against price and returns those that alert.
+func (l *Watchlists) checkPrice(key Key, price float64, shown string) ([]alert, error) {
+	var alerts []alert
+	err := l.file.Update(func(lists *map[string][]*Watch) error {
+		for userID, watches := range *lists {
+			for _, w := range watches {
+				if w.key() == key && w.check(price) {
+					alerts = append(alerts, alert{userID: userID, watch: *w, price: shown})
+				}
+			}
+		}
+		return nil
+	})
+	return alerts, err
+}
+
+// RunWatcher polls the price of every watched item every WatchEvery and
+// tells users when one crosses their target. It returns when ctx is cancelled.
+func (m *Market) RunWatcher(ctx context.Context, session router.Session) {
+	logger := util.LoggerInit("STEAMMARKET", "Watcher")
+	every := time.Duration(m.cfg.WatchEvery)
+	if every <= 0 {
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, key := range m.watches.keys() {
+			price, err := m.prices.Get(ctx, key)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				logger.Warn("Failed to price watched item", "item", key.MarketHashName, "error", err)
+				continue
+			}
+			if lowest, ok := ParsePrice(price.PriceLow); ok {
+				alerts, err := m.watches.checkPrice(key, lowest, price.PriceLow)
+				if err != nil {
+					logger.Error("Failed to save watches", "error", err)
+				}
+				for _, a := range alerts {
+					notify(session, a)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(sampleSpacing):
+			}
+		}
+	}
+}
+
+// notify DMs the user about an alert, falling back to pinging them in the
+// channel where they set the watch up.
+func notify(session router.Session, a alert) {
+	logger := util.LoggerInit("STEAMMARKET", "Watcher")
+	cur := CurrencyByID(a.watch.Currency)
+	msg := fmt.Sprintf("🔔 **%s** is now %s, %s your target of %s.", a.watch.Item, cur.FormatPrice(a.price), a.watch.Direction(), cur.Format(a.watch.Target))
+	if dm, err := session.UserChannelCreate(a.userID); err == nil {
+		if _, err := session.ChannelMessageSend(dm.ID, msg); err == nil {
+			return
+		}
+	}
+	if a.watch.ChannelID == "" {
+		logger.Warn("Could not deliver price alert", "user", a.userID, "item", a.watch.Item)
+		return
+	}
+	if _, err := session.ChannelMessageSend(a.watch.ChannelID, "<@"+a.userID+"> "+msg); err != nil {
+		logger.Warn("Could not deliver price alert", "user", a.userID, "item", a.watch.Item, "error", err)
+	}
+}
@@ -0,0 +1,188 @@
+package tempmail
+
+import (
+	"encoding/json"
+	"html"
+	"regexp"
+	"strings"
+
+	"discordBot/bot/router"
+)
+
+// inboxPageSize is how many mails each page of /inbox shows.
+const inboxPageSize = 10
+
+var (
+	brTag   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	htmlTag = regexp.MustCompile(`(?s)<.*?>`)
+)
+
+// Register adds the temp mail commands to r.
+func Register(r *router.Router) {
+	r.Register(router.Command{
+		Name:        "yopmail",
+		Module:      "mail",
+		Description: "Get a new YOPmail email address.",
+		Handler:     handleYopmail,
+	})
+	r.Register(router.Command{
+		Name:        "mail",
+		Module:      "mail",
+		Description: "Get a new GuerrillaMail email address.",
+		Handler:     handleMail,
+	})
+	r.Register(router.Command{
+		Name:        "inbox",
+		Module:      "mail",
+		Args:        []router.Arg{{Name: "sid_token", Description: "Inbox token from /mail", Required: true}},
+		Description: "Show the GuerrillaMail inbox for your token.",
+		Handler:     handleInbox,
+	})
+	r.Register(router.Command{
+		Name:        "view",
+		Module:      "mail",
+		Args:        []router.Arg{{Name: "mail_id", Description: "Mail ID from /inbox", Required: true}, {Name: "sid_token", Description: "Inbox token from /mail", Required: true}},
+		Description: "View a specific email from your GuerrillaMail inbox.",
+		Handler:     handleView,
+	})
+	r.Register(router.Command{
+		Name:        "del",
+		Module:      "mail",
+		Args:        []router.Arg{{Name: "mail_id", Description: "Mail ID from /inbox", Required: true}, {Name: "sid_token", Description: "Inbox token from /mail", Required: true}},
+		Description: "Delete a specific email from your GuerrillaMail inbox.",
+		Handler:     handleDel,
+	})
+	r.Register(router.Command{
+		Name:        "address",
+		Module:      "mail",
+		Args:        []router.Arg{{Name: "sid_token", Description: "Inbox token from /mail", Required: true}},
+		Description: "Get the GuerrillaMail email address associated with your token.",
+		Handler:     handleAddress,
+	})
+}
+
+func handleYopmail(req *router.Request) {
+	email, domains, err := GetRandomYopmail(req.Context())
+	if err != nil {
+		req.Reply("Failed to generate random email.")
+		return
+	}
+	parts := strings.Split(email, "'")
+	prefix := ""
+	if len(parts) > 1 {
+		prefix = parts[1]
+	}
+	url := "https://yopmail.com/en/inbox?login=" + prefix
+	req.Reply("```Email: " + prefix + "\nInbox: " + url + "\n" + "Alternate Domains:\n" + domains + "```")
+}
+
+func handleMail(req *router.Request) {
+	email, sidToken, err := GetRandomGuerrillaEmail(req.Context())
+	if err != nil {
+		req.Reply("Failed to generate random guerrilla email.")
+		return
+	}
+	req.Reply("```Email: " + email + "\nInbox Token: " + sidToken + "\n *Keep your token safe to access your inbox!*```")
+}
+
+func handleInbox(req *router.Request) {
+	output, err := GetGuerrillaInboxRaw(req.Context(), req.Arg(0))
+	if err != nil {
+		req.Reply("Failed to get inbox: " + err.Error())
+		return
+	}
+	var resp GuerrillaInboxResponse
+	err = json.Unmarshal([]byte(output), &resp)
+	if err != nil {
+		req.Reply("Failed to parse inbox JSON: " + err.Error())
+		return
+	}
+	if len(resp.List) == 0 {
+		req.Reply("No emails found in inbox.")
+		return
+	}
+	lines := make([]string, 0, len(resp.List))
+	for _, mail := range resp.List {
+		lines = append(lines, "`MailID: "+mail.MailID+"` | From: "+mail.MailFrom+" | Subject: "+mail.MailSubject)
+	}
+	req.ReplyPages(router.Paginate("Inbox", lines, inboxPageSize))
+}
+
+func handleView(req *router.Request) {
+	output, err := GetGuerrillaMailContent(req.Context(), req.Arg(0), req.Arg(1))
+	if err != nil {
+		req.Reply("Failed to get email content: " + err.Error())
+		return
+	}
+
+	// Check for boolean or error response
+	var boolCheck bool
+	if err := json.Unmarshal([]byte(output), &boolCheck); err == nil {
+		req.Reply("No email found or invalid response from API.")
+		return
+	}
+
+	var emailContent struct {
+		MailID      string `json:"mail_id"`
+		MailFrom    string `json:"mail_from"`
+		MailSubject string `json:"mail_subject"`
+		MailExcerpt string `json:"mail_excerpt"`
+		MailBody    string `json:"mail_body"`
+	}
+	err = json.Unmarshal([]byte(output), &emailContent)
+	if err != nil {
+		req.Reply("Failed to parse email content JSON: " + err.Error())
+		return
+	}
+
+	msg := "```***Email Content:***\n"
+	msg += "From: " + emailContent.MailFrom + "\n"
+	msg += "Subject: " + emailContent.MailSubject + "\n"
+	msg += "Body:\n" + cleanMailBody(emailContent.MailBody) + "```"
+	req.Reply(msg)
+}
+
+// cleanMailBody turns an HTML mail body into plain text without blank lines.
+func cleanMailBody(body string) string {
+	body = brTag.ReplaceAllString(body, "\n")
+	body = htmlTag.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+
+	lines := strings.Split(body, "\n")
+	var nonEmptyLines []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			nonEmptyLines = append(nonEmptyLines, line)
+		}
+	}
+	return strings.Join(nonEmptyLines, "\n")
+}
+
+func handleDel(req *router.Request) {
+	output, err := DeleteGuerrillaMail(req.Context(), req.Arg(0), req.Arg(1))
+	if err != nil {
+		req.Reply("Failed to delete email: " + err.Error())
+		return
+	}
+	// Respond with 'deleted' if the API response is empty or valid JSON (success)
+	trimmed := strings.TrimSpace(output)
+	if trimmed == "" {
+		req.Reply("```deleted```")
+		return
+	}
+	var resp interface{}
+	if err := json.Unmarshal([]byte(trimmed), &resp); err == nil {
+		req.Reply("```deleted```")
+	} else {
+		req.Reply("Delete API returned: " + trimmed)
+	}
+}
+
+func handleAddress(req *router.Request) {
+	emailAddr, err := GetGuerrillaEmailAddress(req.Context(), req.Arg(0), "en")
+	if err != nil {
+		req.Reply("Failed to get email address!")
+		return
+	}
+	req.Reply("```\nEmail: " + emailAddr + "```")
+}
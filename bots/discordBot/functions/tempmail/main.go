@@ -1,9 +1,11 @@
 package tempmail
 
 import (
+	"context"
 	"discordBot/util"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -156,22 +158,63 @@ func (m *GuerrillaMailItem) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// guerrillaAPI is the GuerrillaMail JSON API every guerrilla function calls.
+const guerrillaAPI = "https://api.guerrillamail.com/ajax.php"
+
+// fetch GETs rawURL and returns the body. cookies are sent with the request.
+func fetch(ctx context.Context, rawURL string, cookies ...*http.Cookie) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return string(body), nil
+}
+
+// guerrilla calls the GuerrillaMail API function f with params.
+func guerrilla(ctx context.Context, f string, params url.Values, cookies ...*http.Cookie) (string, error) {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("f", f)
+	return fetch(ctx, guerrillaAPI+"?"+params.Encode(), cookies...)
+}
+
 // GetRandomYopmail fetches a random email from yopmail.com/en/email-generator
-func GetRandomYopmail() (string, string, error) {
-	resp, err := http.Get("https://yopmail.com/en/email-generator")
+func GetRandomYopmail(ctx context.Context) (string, string, error) {
+	logger := util.LoggerInit("tempmail", "GetRandomYopmail")
+	yopRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://yopmail.com/en/email-generator", nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build yopmail request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(yopRequest)
 	if err != nil {
-		fmt.Printf("[DEBUG] Failed to fetch yopmail page: %v\n", err)
+		logger.Error("Failed to fetch yopmail page", "error", err)
 		return "", "", fmt.Errorf("failed to fetch yopmail page: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		fmt.Printf("[DEBUG] Non-200 status code: %d\n", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		logger.Warn("Unexpected yopmail status", "status", resp.StatusCode)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		fmt.Printf("[DEBUG] Failed to parse yopmail HTML: %v\n", err)
+		logger.Error("Failed to parse yopmail HTML", "error", err)
 		return "", "", fmt.Errorf("failed to parse yopmail HTML: %w", err)
 	}
 
@@ -181,19 +224,18 @@ func GetRandomYopmail() (string, string, error) {
 	if at := strings.Index(rawemail, "@"); at != -1 {
 		email = rawemail[:at]
 	}
-	alternateDomains, err := getYopAlternateDomains()
+	alternateDomains, err := getYopAlternateDomains(ctx)
 	if err != nil {
-		fmt.Printf("[DEBUG] Failed to get alternate domains: %v\n", err)
+		logger.Error("Failed to get alternate domains", "error", err)
 		return "", "", err
 	}
 	return email, strings.Join(alternateDomains, ", "), nil
 }
 
 // GetAlternateDomains fetches alternate domains from yopmail.com/en/alternate-domains
-func getYopAlternateDomains() ([]string, error) {
-	// Use curl to fetch the page and extract <div> contents using regex
-	cmd := "curl -s 'https://yopmail.com/en/domain?d=all'"
-	output, err := util.ExecCommandOutput(cmd)
+func getYopAlternateDomains(ctx context.Context) ([]string, error) {
+	// Fetch the page and extract <div> contents using regex
+	output, err := fetch(ctx, "https://yopmail.com/en/domain?d=all")
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch alternate domains page: %w", err)
 	}
@@ -220,72 +262,60 @@ func getYopAlternateDomains() ([]string, error) {
 }
 
 // geurrilla temp mail
-func GetRandomGuerrillaEmail() (string, string, error) {
-	util.LoggerInit("tempmail", "GetGuerrillaMail")
-	cmd := "curl -s https://api.guerrillamail.com/ajax.php?f=get_email_address"
-	output, err := util.ExecCommandOutput(cmd)
+func GetRandomGuerrillaEmail(ctx context.Context) (string, string, error) {
+	output, err := guerrilla(ctx, "get_email_address", nil)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to fetch guerrilla mail: %w", err)
 	}
 
-	var resp map[string]interface{}
+	var resp struct {
+		EmailAddr string `json:"email_addr"`
+		SidToken  string `json:"sid_token"`
+	}
 	err = json.Unmarshal([]byte(output), &resp)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to parse guerrilla mail response: %w", err)
 	}
-	err = geurrillaSetAddress(resp["sid_token"].(string))
+	err = geurrillaSetAddress(ctx, resp.SidToken)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to set guerrilla email address: %w", err)
 	}
 
-	return resp["email_addr"].(string), resp["sid_token"].(string), nil
+	return resp.EmailAddr, resp.SidToken, nil
 }
 
 // GetGuerrillaInboxRaw fetches the raw JSON inbox string from Guerrilla Mail API
-func GetGuerrillaInboxRaw(sidToken string) (string, error) {
-	logger := util.LoggerInit("tempmail", "GetGuerrillaInboxRaw")
-	url := "https://api.guerrillamail.com/ajax.php?f=get_email_list&offset=0&sid_token=" + sidToken
-	logger.Info(url)
-	output, err := util.ExecCommandOutput("curl -s '" + url + "'")
+func GetGuerrillaInboxRaw(ctx context.Context, sidToken string) (string, error) {
+	output, err := guerrilla(ctx, "get_email_list", url.Values{"offset": {"0"}, "sid_token": {sidToken}})
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch guerrilla inbox list: %w", err)
 	}
 	return output, nil
 }
 
-func geurrillaSetAddress(uid string) error {
+func geurrillaSetAddress(ctx context.Context, uid string) error {
 	logger := util.LoggerInit("tempmail", "geurrillaSetAddress")
-	cmd := fmt.Sprintf("curl -s 'https://api.guerrillamail.com/ajax.php?f=set_email_user&email_user=%s&lang=en&sid_token='", uid)
-	output, err := util.ExecCommandOutput(cmd)
+	_, err := guerrilla(ctx, "set_email_user", url.Values{"email_user": {uid}, "lang": {"en"}, "sid_token": {""}})
 	if err != nil {
-		logger.Error("failed to set guerrilla email address:", "error", err, "output<%s>", output)
+		logger.Error("failed to set guerrilla email address", "error", err)
 		return fmt.Errorf("failed to set guerrilla email address: %w", err)
 	}
 	return nil
 }
 
-func GetGuerrillaMailContent(mailID string, sidToken string) (string, error) {
-	// logger := util.LoggerInit("tempmail", "GetGuerrillaMailContent")
-	emailIDEsc := url.QueryEscape(mailID)
-	sidTokenEsc := url.QueryEscape(sidToken)
-	url := fmt.Sprintf("https://api.guerrillamail.com/ajax.php?f=fetch_email&email_id=%s&sid_token=%s", emailIDEsc, sidTokenEsc)
-	// logger.Info(url) // Suppressed noisy log
-	output, err := util.ExecCommandOutput("curl -s '" + url + "'")
+func GetGuerrillaMailContent(ctx context.Context, mailID string, sidToken string) (string, error) {
+	output, err := guerrilla(ctx, "fetch_email", url.Values{"email_id": {mailID}, "sid_token": {sidToken}})
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch guerrilla mail content: %w", err)
 	}
 	return output, nil
 }
 
-func DeleteGuerrillaMail(mailID string, sidToken string) (string, error) {
+func DeleteGuerrillaMail(ctx context.Context, mailID string, sidToken string) (string, error) {
 	logger := util.LoggerInit("tempmail", "DeleteGuerrillaMail")
-	sidTokenEsc := url.QueryEscape(sidToken)
-	emailIDsParam := url.QueryEscape("email_ids[]") + "=" + url.QueryEscape(mailID)
-	url := fmt.Sprintf("https://api.guerrillamail.com/ajax.php?f=del_email&%s&sid_token=%s", emailIDsParam, sidTokenEsc)
-	logger.Info(url)
-	output, err := util.ExecCommandOutput("curl -s '" + url + "'")
+	output, err := guerrilla(ctx, "del_email", url.Values{"email_ids[]": {mailID}, "sid_token": {sidToken}})
 	if err != nil {
-		logger.Error("failed to delete guerrilla mail:", "error", err, "output<%s>", output)
+		logger.Error("failed to delete guerrilla mail", "error", err)
 		return "", fmt.Errorf("failed to delete guerrilla mail: %w", err)
 	}
 	return output, nil
@@ -296,13 +326,11 @@ type GuerrillaAddressResponse struct {
 	EmailAddr string `json:"email_addr"`
 }
 
-func GetGuerrillaEmailAddress(sidToken string, lang string) (string, error) {
+func GetGuerrillaEmailAddress(ctx context.Context, sidToken string, lang string) (string, error) {
 	if lang == "" {
 		lang = "en"
 	}
-	apiUrl := "https://api.guerrillamail.com/ajax.php?f=get_email_address&lang=" + url.QueryEscape(lang)
-	cmd := fmt.Sprintf("curl -s -H 'Cookie: PHPSESSID=%s' '%s'", sidToken, apiUrl)
-	output, err := util.ExecCommandOutput(cmd)
+	output, err := guerrilla(ctx, "get_email_address", url.Values{"lang": {lang}}, &http.Cookie{Name: "PHPSESSID", Value: sidToken})
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch guerrilla email address: %w", err)
 	}
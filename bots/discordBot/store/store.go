@@ -0,0 +1,77 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Dir is where the bot keeps its state files. It can be moved with DATA_DIR.
+func Dir() string {
+	if dir := os.Getenv("DATA_DIR"); dir != "" {
+		return dir
+	}
+	return "data"
+}
+
+// File holds a value of type T in memory and writes it back to a JSON file
+// after every update.
+type File[T any] struct {
+	mu   sync.RWMutex
+	path string
+	data T
+}
+
+// Open loads name from Dir(), starting from init if the file doesn't exist yet.
+func Open[T any](name string, init T) (*File[T], error) {
+	f := &File[T]{path: filepath.Join(Dir(), name), data: init}
+	raw, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	if err := json.Unmarshal(raw, &f.data); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", f.path, err)
+	}
+	return f, nil
+}
+
+// View calls fn with the current value under a read lock. fn must not keep
+// references into the value after it returns.
+func (f *File[T]) View(fn func(v *T)) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	fn(&f.data)
+}
+
+// Update calls fn under a write lock and saves the result. If fn returns an
+// error nothing is written, but changes fn already made in memory stay.
+func (f *File[T]) Update(fn func(v *T) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := fn(&f.data); err != nil {
+		return err
+	}
+	return f.save()
+}
+
+// save writes to a temp file and renames it, so a crash never leaves a half-written file.
+func (f *File[T]) save() error {
+	raw, err := json.MarshalIndent(f.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", f.path, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(f.path), err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	return os.Rename(tmp, f.path)
+}
@@ -1,10 +1,9 @@
 package util
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"log/slog"
-	"net/url"
 
 	"os"
 	"os/exec"
@@ -14,10 +13,6 @@ import (
 	"github.com/joho/godotenv"
 )
 
-const (
-	STEAM_MARKET_PRICE_OVERVIEW_URL = "https://steamcommunity.com/market/priceoverview/?appid=730&currency=2&market_hash_name="
-)
-
 func GetToken() string {
 	logger := LoggerInit("GET BOT", "BOT")
 	logger.Info("Getting bot token")
@@ -96,9 +91,12 @@ func SplitArgs(input string) []string {
 	}
 	return args
 }
-func ExecBinary(binaryPath string, command string, args ...string) (string, error) {
+
+// ExecBinary runs a bundled binary and returns its combined output. The
+// process is killed if ctx is cancelled.
+func ExecBinary(ctx context.Context, binaryPath string, command string, args ...string) (string, error) {
 	logger := LoggerInit("UTIL", "ExecBinary")
-	binaryCmd := exec.Command(binaryPath, append([]string{command}, args...)...)
+	binaryCmd := exec.CommandContext(ctx, binaryPath, append([]string{command}, args...)...)
 	output, err := binaryCmd.CombinedOutput()
 	if err != nil {
 		logger.Error("Failed to execute binary", "error", err, "output", string(output))
@@ -107,29 +105,6 @@ func ExecBinary(binaryPath string, command string, args ...string) (string, erro
 	return string(output), nil
 }
 
-// execCommandOutput runs a shell command and returns its output as a string
-func FormatForSteamMarketInjection(input string) (string, error) {
-	formatted := fmt.Sprintf(STEAM_MARKET_PRICE_OVERVIEW_URL+"%s", input)
-	if formatted == "" {
-		return "", fmt.Errorf("failed to format Steam Market Injection URL")
-	}
-	return formatted, nil
-}
-
-// execCommandOutput runs a shell command and returns its output as a string
-
-func ExecCommandOutput(cmd string) (string, error) {
-	out, err := exec.Command("sh", "-c", cmd).Output()
-	if err != nil {
-		return "", err
-	}
-	return string(out), nil
-}
-
-func MarketHashName(param string) string {
-	return url.QueryEscape(param)
-}
-
 func ParseJsonOutput(output string) (map[string]interface{}, error) {
 	logger := LoggerInit("UTIL", "ParseJsonOutput")
 	var result map[string]interface{}